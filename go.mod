@@ -7,6 +7,7 @@ require (
 	github.com/cloudevents/sdk-go/v2 v2.4.1
 	github.com/google/go-cmp v0.5.2 // indirect
 	github.com/google/uuid v1.3.0
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/stretchr/testify v1.7.0
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
 )
@@ -0,0 +1,23 @@
+package models
+
+import "encoding/json"
+
+type CreateSubscriptionResponse struct {
+	ID string `json:"id"`
+}
+
+func (s *CreateSubscriptionResponse) ToJSON() ([]byte, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+func (s *CreateSubscriptionResponse) FromJSON(b []byte) error {
+	var res CreateSubscriptionResponse
+	if err := json.Unmarshal(b, &res); err != nil {
+		return err
+	}
+	*s = res
+	return nil
+}
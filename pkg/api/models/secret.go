@@ -18,6 +18,9 @@ type SecretMetadata struct {
 	// The scope of the secret
 	// Required: true
 	Scope *string `json:"scope,omitempty" yaml:"scope,omitempty"`
+
+	// The keys of the data of the secret. Only set when retrieving secrets
+	Keys []string `json:"keys,omitempty" yaml:"keys,omitempty"`
 }
 
 type GetSecretsResponse struct {
@@ -13,6 +13,8 @@ type Integration struct {
 	Name         string       `json:"name" bson:"name"`
 	MetaData     MetaData     `json:"metadata" bson:"metadata"`
 	Subscription Subscription `json:"subscription" bson:"subscription"`
+	// Subscriptions of the integration, which are managed independently of its registration
+	Subscriptions []Subscription `json:"subscriptions,omitempty" bson:"subscriptions,omitempty"`
 }
 
 type MetaData struct {
@@ -25,6 +27,8 @@ type MetaData struct {
 }
 
 type Subscription struct {
+	// ID of the subscription, which is only set for subscriptions managed independently of the registration of the integration
+	ID     string             `json:"id,omitempty" bson:"id,omitempty"`
 	Topics []string           `json:"topics" bson:"topics"`
 	Status string             `json:"status" bson:"status"`
 	Filter SubscriptionFilter `json:"filter" bson:"filter"`
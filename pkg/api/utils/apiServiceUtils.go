@@ -106,6 +106,9 @@ func put(uri string, data []byte, api APIService) (string, *models.Error) {
 			return "", buildErrorResponse(err.Error() + "\n" + "-----DETAILS-----" + string(body))
 		}
 
+		if respErr.Code == 0 {
+			respErr.Code = int64(resp.StatusCode)
+		}
 		return "", &respErr
 	}
 
@@ -192,6 +195,9 @@ func post(uri string, data []byte, api APIService) (string, *models.Error) {
 			return "", buildErrorResponse(err.Error() + "\n" + "-----DETAILS-----" + string(body))
 		}
 
+		if respErr.Code == 0 {
+			respErr.Code = int64(resp.StatusCode)
+		}
 		return "", &respErr
 	}
 
@@ -238,7 +244,7 @@ func deleteWithEventContext(uri string, api APIService) (*models.EventContext, *
 	return nil, &respErr
 }
 
-func delete(uri string, api APIService) (string, *models.Error) {
+func deleteRequest(uri string, api APIService) (string, *models.Error) {
 	req, err := http.NewRequest("DELETE", uri, nil)
 	req.Header.Set("Content-Type", "application/json")
 	addAuthHeader(req, api)
@@ -269,6 +275,9 @@ func delete(uri string, api APIService) (string, *models.Error) {
 		return "", buildErrorResponse(err.Error() + "\n" + "-----DETAILS-----" + string(body))
 	}
 
+	if respErr.Code == 0 {
+		respErr.Code = int64(resp.StatusCode)
+	}
 	return "", &respErr
 }
 
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestErrorsContainStatusCode(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/with-code":
+			// the code contained in the body takes precedence over the status code
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code": 422, "message": "invalid"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "not found"}`))
+		}
+	}))
+	defer ts.Close()
+
+	handler := NewAuthenticatedAPIHandler(ts.URL, "", "", nil, "http")
+	requests := map[string]func(uri string) (string, *models.Error){
+		"post": func(uri string) (string, *models.Error) {
+			return post(uri, []byte(`{}`), handler)
+		},
+		"put": func(uri string) (string, *models.Error) {
+			return put(uri, []byte(`{}`), handler)
+		},
+		"delete": func(uri string) (string, *models.Error) {
+			return deleteRequest(uri, handler)
+		},
+	}
+	for name, request := range requests {
+		t.Run(name, func(t *testing.T) {
+			_, errObj := request(ts.URL + "/without-code")
+			require.NotNil(t, errObj)
+			assert.Equal(t, int64(http.StatusNotFound), errObj.Code)
+			assert.Equal(t, "not found", errObj.GetMessage())
+
+			_, errObj = request(ts.URL + "/with-code")
+			require.NotNil(t, errObj)
+			assert.Equal(t, int64(422), errObj.Code)
+			assert.Equal(t, "invalid", errObj.GetMessage())
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/go-utils/pkg/common/osutils"
+)
+
+// Environment variables the metadata of an integration is read from by NewIntegrationFromEnv
+const (
+	IntegrationDeploymentNameEnvVar     = "K8S_DEPLOYMENT_NAME"
+	IntegrationPodNameEnvVar            = "K8S_POD_NAME"
+	IntegrationNamespaceEnvVar          = "K8S_NAMESPACE"
+	IntegrationNodeNameEnvVar           = "K8S_NODE_NAME"
+	IntegrationVersionEnvVar            = "VERSION"
+	IntegrationDistributorVersionEnvVar = "DISTRIBUTOR_VERSION"
+	IntegrationLocationEnvVar           = "LOCATION"
+)
+
+// DefaultIntegrationHeartbeatInterval is the default interval in which integrations are registered again
+const DefaultIntegrationHeartbeatInterval = 30 * time.Second
+
+// DefaultIntegrationHeartbeatMinBackoff is the default delay before retrying a failed registration
+const DefaultIntegrationHeartbeatMinBackoff = time.Second
+
+// DefaultIntegrationHeartbeatMaxBackoff is the default maximum delay before retrying a failed registration
+const DefaultIntegrationHeartbeatMaxBackoff = time.Minute
+
+// IntegrationRegistrar is the api to register and unregister integrations, which is implemented by the UniformHandler
+type IntegrationRegistrar interface {
+	RegisterIntegration(integration models.Integration) (string, error)
+	UnregisterIntegration(integrationID string) error
+}
+
+// NewIntegrationFromEnv creates an integration with the given name and subscription, whose metadata is read from the
+// environment variables K8S_DEPLOYMENT_NAME, K8S_POD_NAME, K8S_NAMESPACE, K8S_NODE_NAME, VERSION, DISTRIBUTOR_VERSION
+// and LOCATION. If the name is empty, the name of the deployment is used. The hostname defaults to the name of the host
+// if K8S_NODE_NAME is not set.
+// The ID of the integration is derived from its name, namespace and subscription filter using models.IntegrationID,
+// so that it stays the same across restarts. Hence, the name and the namespace are required
+func NewIntegrationFromEnv(name string, subscription models.Subscription) (*models.Integration, error) {
+	deploymentName := osutils.GetOSEnv(IntegrationDeploymentNameEnvVar)
+	if name == "" {
+		name = deploymentName
+	}
+	hostname := osutils.GetOSEnv(IntegrationNodeNameEnvVar)
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+
+	integration := &models.Integration{
+		Name: name,
+		MetaData: models.MetaData{
+			Hostname:           hostname,
+			IntegrationVersion: osutils.GetOSEnv(IntegrationVersionEnvVar),
+			DistributorVersion: osutils.GetOSEnv(IntegrationDistributorVersionEnvVar),
+			Location:           osutils.GetOSEnv(IntegrationLocationEnvVar),
+			KubernetesMetaData: models.KubernetesMetaData{
+				Namespace:      osutils.GetOSEnv(IntegrationNamespaceEnvVar),
+				PodName:        osutils.GetOSEnv(IntegrationPodNameEnvVar),
+				DeploymentName: deploymentName,
+			},
+		},
+		Subscription: subscription,
+	}
+
+	id, err := models.IntegrationID{
+		Name:      integration.Name,
+		Namespace: integration.MetaData.KubernetesMetaData.Namespace,
+		Project:   subscription.Filter.Project,
+		Stage:     subscription.Filter.Stage,
+		Service:   subscription.Filter.Service,
+	}.Hash()
+	if err != nil {
+		return nil, err
+	}
+	integration.ID = id
+	return integration, nil
+}
+
+// IntegrationLifecycle registers an integration and keeps its registration alive by registering it again periodically,
+// which updates the time it was last seen. The integration is unregistered once the lifecycle is stopped
+type IntegrationLifecycle struct {
+	registrar         IntegrationRegistrar
+	integration       models.Integration
+	heartbeatInterval time.Duration
+	minBackoff        time.Duration
+	maxBackoff        time.Duration
+	retryOnStart      bool
+	clock             clock.Clock
+
+	mutex   sync.Mutex
+	id      string
+	started bool
+	done    chan struct{}
+}
+
+// IntegrationLifecycleOption can be used to configure an IntegrationLifecycle
+type IntegrationLifecycleOption func(*IntegrationLifecycle)
+
+// WithHeartbeatInterval sets the interval in which the integration is registered again
+func WithHeartbeatInterval(interval time.Duration) IntegrationLifecycleOption {
+	return func(lc *IntegrationLifecycle) {
+		lc.heartbeatInterval = interval
+	}
+}
+
+// WithHeartbeatBackoff sets the delay before retrying a failed registration, which is doubled after each failed
+// attempt up to the given maximum
+func WithHeartbeatBackoff(min time.Duration, max time.Duration) IntegrationLifecycleOption {
+	return func(lc *IntegrationLifecycle) {
+		lc.minBackoff = min
+		lc.maxBackoff = max
+	}
+}
+
+// WithRegistrationRetryOnStart makes Start succeed even if the initial registration fails, e.g. because the shipyard
+// controller is not available yet. In this case, the failure is logged and the heartbeat retries the registration
+// with backoff, see WithHeartbeatBackoff
+func WithRegistrationRetryOnStart() IntegrationLifecycleOption {
+	return func(lc *IntegrationLifecycle) {
+		lc.retryOnStart = true
+	}
+}
+
+// WithIntegrationLifecycleClock sets the clock used for the heartbeat and for the time the integration was last seen
+func WithIntegrationLifecycleClock(c clock.Clock) IntegrationLifecycleOption {
+	return func(lc *IntegrationLifecycle) {
+		lc.clock = c
+	}
+}
+
+// NewIntegrationLifecycle creates a new IntegrationLifecycle for the integration, which is usually created using
+// NewIntegrationFromEnv
+func NewIntegrationLifecycle(registrar IntegrationRegistrar, integration models.Integration, opts ...IntegrationLifecycleOption) *IntegrationLifecycle {
+	lc := &IntegrationLifecycle{
+		registrar:         registrar,
+		integration:       integration,
+		heartbeatInterval: DefaultIntegrationHeartbeatInterval,
+		minBackoff:        DefaultIntegrationHeartbeatMinBackoff,
+		maxBackoff:        DefaultIntegrationHeartbeatMaxBackoff,
+		clock:             clock.New(),
+		done:              make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(lc)
+	}
+	if lc.maxBackoff < lc.minBackoff {
+		lc.maxBackoff = lc.minBackoff
+	}
+	return lc
+}
+
+// Start registers the integration and starts the heartbeat in the background. If the registration fails, the error is
+// returned and no heartbeat is started, unless the lifecycle has been created with WithRegistrationRetryOnStart.
+// Once the context is done, the heartbeat is stopped and the integration is unregistered, after which the channel
+// returned by Done is closed
+func (lc *IntegrationLifecycle) Start(ctx context.Context) (string, error) {
+	lc.mutex.Lock()
+	if lc.started {
+		lc.mutex.Unlock()
+		return "", errors.New("integration lifecycle has already been started")
+	}
+	lc.started = true
+	lc.mutex.Unlock()
+
+	backoff := time.Duration(0)
+	if err := lc.register(); err != nil {
+		if !lc.retryOnStart {
+			lc.mutex.Lock()
+			lc.started = false
+			lc.mutex.Unlock()
+			return "", err
+		}
+		// until the registration succeeds, the integration is identified by the ID it is going to be registered with
+		lc.mutex.Lock()
+		lc.id = lc.integration.ID
+		lc.mutex.Unlock()
+		backoff = lc.nextBackoff(backoff)
+		log.Printf("Could not register integration %s, retrying in %s: %s", lc.integration.Name, backoff, err.Error())
+	}
+	go lc.heartbeat(ctx, backoff)
+	return lc.ID(), nil
+}
+
+// ID returns the ID the integration has been registered with
+func (lc *IntegrationLifecycle) ID() string {
+	lc.mutex.Lock()
+	defer lc.mutex.Unlock()
+	return lc.id
+}
+
+// Done returns a channel which is closed once the integration has been unregistered after the lifecycle was stopped
+func (lc *IntegrationLifecycle) Done() <-chan struct{} {
+	return lc.done
+}
+
+// heartbeat registers the integration again after the heartbeat interval, or after the given backoff if the previous
+// registration has failed
+func (lc *IntegrationLifecycle) heartbeat(ctx context.Context, backoff time.Duration) {
+	defer close(lc.done)
+
+	for {
+		delay := lc.heartbeatInterval
+		if backoff > 0 {
+			delay = backoff
+		}
+		timer := lc.clock.Timer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if err := lc.registrar.UnregisterIntegration(lc.ID()); err != nil {
+				log.Printf("Could not unregister integration %s: %s", lc.integration.Name, err.Error())
+			}
+			return
+		case <-timer.C:
+		}
+
+		if err := lc.register(); err != nil {
+			backoff = lc.nextBackoff(backoff)
+			log.Printf("Could not register integration %s, retrying in %s: %s", lc.integration.Name, backoff, err.Error())
+			continue
+		}
+		backoff = 0
+	}
+}
+
+func (lc *IntegrationLifecycle) register() error {
+	integration := lc.integration
+	integration.MetaData.LastSeen = lc.clock.Now().UTC()
+	id, err := lc.registrar.RegisterIntegration(integration)
+	if err != nil {
+		return err
+	}
+	if id == "" {
+		id = integration.ID
+	}
+	lc.mutex.Lock()
+	lc.id = id
+	lc.mutex.Unlock()
+	return nil
+}
+
+func (lc *IntegrationLifecycle) nextBackoff(backoff time.Duration) time.Duration {
+	if backoff == 0 {
+		return lc.minBackoff
+	}
+	backoff *= 2
+	if backoff > lc.maxBackoff {
+		return lc.maxBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ IntegrationRegistrar = &UniformHandler{}
+
+type fakeIntegrationRegistrar struct {
+	mutex         sync.Mutex
+	registrations []models.Integration
+	unregistered  []string
+	failures      int
+}
+
+func (f *fakeIntegrationRegistrar) RegisterIntegration(integration models.Integration) (string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return "", errors.New("shipyard controller not available")
+	}
+	f.registrations = append(f.registrations, integration)
+	return integration.ID, nil
+}
+
+func (f *fakeIntegrationRegistrar) UnregisterIntegration(integrationID string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.unregistered = append(f.unregistered, integrationID)
+	return nil
+}
+
+func (f *fakeIntegrationRegistrar) registrationCount() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return len(f.registrations)
+}
+
+func setEnv(t *testing.T, env map[string]string) {
+	for key, value := range env {
+		previous, ok := os.LookupEnv(key)
+		os.Setenv(key, value)
+		key := key
+		t.Cleanup(func() {
+			if ok {
+				os.Setenv(key, previous)
+			} else {
+				os.Unsetenv(key)
+			}
+		})
+	}
+}
+
+func TestNewIntegrationFromEnv(t *testing.T) {
+	setEnv(t, map[string]string{
+		IntegrationDeploymentNameEnvVar:     "jmeter-service",
+		IntegrationPodNameEnvVar:            "jmeter-service-abc",
+		IntegrationNamespaceEnvVar:          "keptn",
+		IntegrationNodeNameEnvVar:           "node-1",
+		IntegrationVersionEnvVar:            "0.8.4",
+		IntegrationDistributorVersionEnvVar: "0.8.5",
+		IntegrationLocationEnvVar:           "control-plane",
+	})
+	subscription := models.Subscription{Topics: []string{"sh.keptn.event.test.triggered"}, Filter: models.SubscriptionFilter{Project: "sockshop"}}
+
+	integration, err := NewIntegrationFromEnv("", subscription)
+	require.Nil(t, err)
+	assert.Equal(t, "jmeter-service", integration.Name)
+	assert.Equal(t, models.MetaData{
+		Hostname:           "node-1",
+		IntegrationVersion: "0.8.4",
+		DistributorVersion: "0.8.5",
+		Location:           "control-plane",
+		KubernetesMetaData: models.KubernetesMetaData{Namespace: "keptn", PodName: "jmeter-service-abc", DeploymentName: "jmeter-service"},
+	}, integration.MetaData)
+	assert.Equal(t, subscription, integration.Subscription)
+
+	expectedID, _ := models.IntegrationID{Name: "jmeter-service", Namespace: "keptn", Project: "sockshop"}.Hash()
+	assert.Equal(t, expectedID, integration.ID)
+
+	// the ID is stable across restarts, i.e. it does not depend on the pod
+	setEnv(t, map[string]string{IntegrationPodNameEnvVar: "jmeter-service-def"})
+	restarted, err := NewIntegrationFromEnv("", subscription)
+	require.Nil(t, err)
+	assert.Equal(t, integration.ID, restarted.ID)
+
+	setEnv(t, map[string]string{IntegrationNamespaceEnvVar: ""})
+	_, err = NewIntegrationFromEnv("", subscription)
+	assert.NotNil(t, err)
+}
+
+func TestIntegrationLifecycle(t *testing.T) {
+	registrar := &fakeIntegrationRegistrar{}
+	lc := NewIntegrationLifecycle(registrar, models.Integration{ID: "my-id", Name: "my-integration"}, WithHeartbeatInterval(10*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	id, err := lc.Start(ctx)
+	require.Nil(t, err)
+	assert.Equal(t, "my-id", id)
+
+	_, err = lc.Start(ctx)
+	assert.NotNil(t, err)
+
+	assert.Eventually(t, func() bool { return registrar.registrationCount() >= 3 }, time.Second, 5*time.Millisecond)
+	cancel()
+	select {
+	case <-lc.Done():
+	case <-time.After(time.Second):
+		t.Fatal("integration lifecycle did not stop")
+	}
+
+	registrar.mutex.Lock()
+	defer registrar.mutex.Unlock()
+	assert.Equal(t, []string{"my-id"}, registrar.unregistered)
+	assert.True(t, registrar.registrations[1].MetaData.LastSeen.After(registrar.registrations[0].MetaData.LastSeen))
+}
+
+func TestIntegrationLifecycleRetriesWithBackoff(t *testing.T) {
+	registrar := &fakeIntegrationRegistrar{}
+	lc := NewIntegrationLifecycle(registrar, models.Integration{ID: "my-id", Name: "my-integration"},
+		WithHeartbeatInterval(10*time.Millisecond),
+		WithHeartbeatBackoff(time.Millisecond, 4*time.Millisecond),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err := lc.Start(ctx)
+	require.Nil(t, err)
+
+	registrar.mutex.Lock()
+	registrar.failures = 5
+	registrar.mutex.Unlock()
+
+	assert.Eventually(t, func() bool { return registrar.registrationCount() >= 2 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 4*time.Millisecond, lc.nextBackoff(4*time.Millisecond))
+	assert.Equal(t, time.Millisecond, lc.nextBackoff(0))
+}
+
+func TestIntegrationLifecycleFailingRegistration(t *testing.T) {
+	registrar := &fakeIntegrationRegistrar{failures: 1}
+	lc := NewIntegrationLifecycle(registrar, models.Integration{ID: "my-id", Name: "my-integration"})
+
+	_, err := lc.Start(context.Background())
+	assert.NotNil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	id, err := lc.Start(ctx)
+	require.Nil(t, err)
+	assert.Equal(t, "my-id", id)
+	cancel()
+	<-lc.Done()
+}
+
+func TestIntegrationLifecycleRetriesFailingRegistrationOnStart(t *testing.T) {
+	registrar := &fakeIntegrationRegistrar{failures: 3}
+	lc := NewIntegrationLifecycle(registrar, models.Integration{ID: "my-id", Name: "my-integration"},
+		WithHeartbeatInterval(time.Hour),
+		WithHeartbeatBackoff(time.Millisecond, 4*time.Millisecond),
+		WithRegistrationRetryOnStart(),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	id, err := lc.Start(ctx)
+	require.Nil(t, err)
+	assert.Equal(t, "my-id", id)
+
+	// the heartbeat interval is not waited for until the registration succeeds
+	assert.Eventually(t, func() bool { return registrar.registrationCount() == 1 }, time.Second, time.Millisecond)
+	cancel()
+	<-lc.Done()
+
+	registrar.mutex.Lock()
+	defer registrar.mutex.Unlock()
+	assert.Equal(t, []string{"my-id"}, registrar.unregistered)
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	labelSelectorEquals       = "="
+	labelSelectorNotEquals    = "!="
+	labelSelectorIn           = "in"
+	labelSelectorNotIn        = "notin"
+	labelSelectorExists       = "exists"
+	labelSelectorDoesNotExist = "!"
+)
+
+var labelSelectorSetRegex = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
+
+// labelRequirement is a single requirement of a label selector, e.g. "rollout in (broken,failed)"
+type labelRequirement struct {
+	key      string
+	operator string
+	values   []string
+}
+
+// labelSelector selects labels matching all of its requirements. An empty selector matches all labels
+type labelSelector []labelRequirement
+
+// parseLabelSelector parses a comma separated list of label requirements, using the same syntax as Kubernetes label selectors:
+//
+//	key=value, key==value  the label must have the given value
+//	key!=value             the label must not have the given value, or must not be set
+//	key in (v1,v2)         the label must have one of the given values
+//	key notin (v1,v2)      the label must not have any of the given values, or must not be set
+//	key                    the label must be set
+//	!key                   the label must not be set
+func parseLabelSelector(selector string) (labelSelector, error) {
+	result := labelSelector{}
+	if strings.TrimSpace(selector) == "" {
+		return result, nil
+	}
+	for _, part := range splitLabelSelector(selector) {
+		requirement, err := parseLabelRequirement(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("invalid label selector %q: %s", selector, err.Error())
+		}
+		result = append(result, requirement)
+	}
+	return result, nil
+}
+
+// splitLabelSelector splits the selector at all commas which are not part of a set of values
+func splitLabelSelector(selector string) []string {
+	parts := []string{}
+	depth := 0
+	start := 0
+	for i, c := range selector {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, selector[start:])
+}
+
+func parseLabelRequirement(requirement string) (labelRequirement, error) {
+	if matches := labelSelectorSetRegex.FindStringSubmatch(requirement); matches != nil {
+		values := []string{}
+		for _, value := range strings.Split(matches[3], ",") {
+			value = strings.TrimSpace(value)
+			if err := validateLabelSelectorValue(value); err != nil {
+				return labelRequirement{}, err
+			}
+			values = append(values, value)
+		}
+		return labelRequirement{key: matches[1], operator: matches[2], values: values}, validateLabelSelectorKey(matches[1])
+	}
+	if strings.HasPrefix(requirement, "!") && !strings.Contains(requirement, "=") {
+		key := strings.TrimSpace(strings.TrimPrefix(requirement, "!"))
+		return labelRequirement{key: key, operator: labelSelectorDoesNotExist}, validateLabelSelectorKey(key)
+	}
+
+	operator := labelSelectorEquals
+	separator := ""
+	for _, op := range []string{"!=", "==", "="} {
+		if strings.Contains(requirement, op) {
+			separator = op
+			break
+		}
+	}
+	if separator == "" {
+		return labelRequirement{key: requirement, operator: labelSelectorExists}, validateLabelSelectorKey(requirement)
+	}
+	if separator == "!=" {
+		operator = labelSelectorNotEquals
+	}
+	keyAndValue := strings.SplitN(requirement, separator, 2)
+	key := strings.TrimSpace(keyAndValue[0])
+	value := strings.TrimSpace(keyAndValue[1])
+	if err := validateLabelSelectorKey(key); err != nil {
+		return labelRequirement{}, err
+	}
+	if err := validateLabelSelectorValue(value); err != nil {
+		return labelRequirement{}, err
+	}
+	return labelRequirement{key: key, operator: operator, values: []string{value}}, nil
+}
+
+func validateLabelSelectorKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("label key must not be empty")
+	}
+	return validateLabelSelectorValue(key)
+}
+
+func validateLabelSelectorValue(value string) error {
+	if strings.ContainsAny(value, "!=(), \t\n") {
+		return fmt.Errorf("%q must not contain whitespace or any of the characters !=(),", value)
+	}
+	return nil
+}
+
+// Matches returns true if the labels fulfill all requirements of the selector
+func (s labelSelector) Matches(labels map[string]string) bool {
+	for _, requirement := range s {
+		if !requirement.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r labelRequirement) matches(labels map[string]string) bool {
+	value, ok := labels[r.key]
+	switch r.operator {
+	case labelSelectorEquals, labelSelectorIn:
+		return ok && r.hasValue(value)
+	case labelSelectorNotEquals, labelSelectorNotIn:
+		return !ok || !r.hasValue(value)
+	case labelSelectorExists:
+		return ok
+	case labelSelectorDoesNotExist:
+		return !ok
+	}
+	return false
+}
+
+func (r labelRequirement) hasValue(value string) bool {
+	for _, v := range r.values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
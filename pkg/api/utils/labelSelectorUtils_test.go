@@ -0,0 +1,50 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelSelector_Matches(t *testing.T) {
+	labels := map[string]string{"rollout": "broken", "team": "carts"}
+
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{selector: "", want: true},
+		{selector: "rollout=broken", want: true},
+		{selector: "rollout==broken", want: true},
+		{selector: "rollout=ok", want: false},
+		{selector: "rollout!=ok", want: true},
+		{selector: "canary!=true", want: true},
+		{selector: "rollout!=broken", want: false},
+		{selector: "rollout", want: true},
+		{selector: "canary", want: false},
+		{selector: "!canary", want: true},
+		{selector: "!rollout", want: false},
+		{selector: "team in (carts, orders)", want: true},
+		{selector: "team in (orders)", want: false},
+		{selector: "team notin (orders)", want: true},
+		{selector: "canary notin (true)", want: true},
+		{selector: "team notin (carts,orders)", want: false},
+		{selector: "rollout=broken, team in (carts,orders), !canary", want: true},
+		{selector: "rollout=broken,team notin (carts)", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			selector, err := parseLabelSelector(tt.selector)
+			require.Nil(t, err)
+			assert.Equal(t, tt.want, selector.Matches(labels))
+		})
+	}
+}
+
+func TestParseLabelSelectorInvalid(t *testing.T) {
+	for _, selector := range []string{"=broken", "rollout=broken,", "rollout=a=b", "!", "team in (a b)", "rollout=(broken)"} {
+		_, err := parseLabelSelector(selector)
+		assert.NotNil(t, err, selector)
+	}
+}
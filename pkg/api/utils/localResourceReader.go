@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+)
+
+// LocalResourceReader is a ResourceProvider which reads and writes resources of a local directory.
+// The directory has to be structured like the configuration service stores the resources, i.e.:
+//
+//	<root>/<project>/resource/<resourceURI>
+//	<root>/<project>/stage/<stage>/resource/<resourceURI>
+//	<root>/<project>/stage/<stage>/service/<service>/resource/<resourceURI>
+//
+// Use a ResourceResolver to resolve resources across the levels of a scope
+type LocalResourceReader struct {
+	rootDir string
+}
+
+// NewLocalResourceReader creates a new LocalResourceReader for the given root directory
+func NewLocalResourceReader(rootDir string) *LocalResourceReader {
+	return &LocalResourceReader{rootDir: rootDir}
+}
+
+// GetResource reads the resource with the given URI or returns ResourceNotFoundError if it does not exist
+func (l *LocalResourceReader) GetResource(scope ResourceScope, resourceURI string) (*models.Resource, error) {
+	scopeDir, err := l.getScopeDir(scope)
+	if err != nil {
+		return nil, err
+	}
+	filePath, err := getLocalResourcePath(scopeDir, resourceURI)
+	if err != nil {
+		return nil, err
+	}
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ResourceNotFoundError
+		}
+		return nil, err
+	}
+	uri := resourceURI
+	return &models.Resource{ResourceURI: &uri, ResourceContent: string(content)}, nil
+}
+
+// GetAllResources returns all resources of the scope including their content, ordered by their resource URI
+func (l *LocalResourceReader) GetAllResources(scope ResourceScope) ([]*models.Resource, error) {
+	scopeDir, err := l.getScopeDir(scope)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(scopeDir); errors.Is(err, os.ErrNotExist) {
+		return []*models.Resource{}, nil
+	}
+	resources, err := readLocalResources(scopeDir)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return *resources[i].ResourceURI < *resources[j].ResourceURI
+	})
+	return resources, nil
+}
+
+// PutResource writes the content of the resource to its file, creating the directories of its level if required.
+// Since the local directory does not keep track of versions, the returned version is always empty
+func (l *LocalResourceReader) PutResource(scope ResourceScope, resource *models.Resource) (string, error) {
+	if resource == nil || resource.ResourceURI == nil {
+		return "", &InvalidResourceURIError{Rule: ResourceURIRuleNotEmpty}
+	}
+	scopeDir, err := l.getScopeDir(scope)
+	if err != nil {
+		return "", err
+	}
+	filePath, err := getLocalResourcePath(scopeDir, *resource.ResourceURI)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return "", err
+	}
+	return "", ioutil.WriteFile(filePath, []byte(resource.ResourceContent), 0644)
+}
+
+func (l *LocalResourceReader) getScopeDir(scope ResourceScope) (string, error) {
+	if err := scope.Validate(); err != nil {
+		return "", err
+	}
+	elements := []string{scope.Project}
+	if scope.Stage != "" {
+		elements = append(elements, "stage", scope.Stage)
+	}
+	if scope.Service != "" {
+		elements = append(elements, "service", scope.Service)
+	}
+	for _, element := range elements {
+		if element == "." || element == ".." || filepath.Base(element) != element {
+			return "", errors.New("invalid resource scope: " + scope.String())
+		}
+	}
+	return filepath.Join(append(append([]string{l.rootDir}, elements...), "resource")...), nil
+}
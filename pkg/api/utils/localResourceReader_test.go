@@ -0,0 +1,73 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ ResourceProvider = &LocalResourceReader{}
+var _ ResourceProvider = &ResourceHandler{}
+
+func TestLocalResourceReader(t *testing.T) {
+	rootDir := t.TempDir()
+	writeTestFiles(t, rootDir, map[string]string{
+		"sockshop/resource/shipyard.yaml":                            "kind: Shipyard",
+		"sockshop/stage/dev/resource/slo.yaml":                       "stage-slo",
+		"sockshop/stage/dev/service/carts/resource/helm/values.yaml": "replicas: 1",
+		"sockshop/stage/dev/service/carts/resource/slo.yaml":         "service-slo",
+	})
+	reader := NewLocalResourceReader(rootDir)
+
+	resource, err := reader.GetResource(ResourceScope{Project: "sockshop"}, "/shipyard.yaml")
+	require.Nil(t, err)
+	assert.Equal(t, "kind: Shipyard", resource.ResourceContent)
+	assert.Equal(t, "/shipyard.yaml", *resource.ResourceURI)
+
+	_, err = reader.GetResource(ResourceScope{Project: "sockshop", Stage: "dev"}, "helm/values.yaml")
+	assert.Equal(t, ResourceNotFoundError, err)
+
+	_, err = reader.GetResource(ResourceScope{Project: "sockshop", Stage: "dev"}, "../../../resource/shipyard.yaml")
+	assert.NotNil(t, err)
+	assert.NotEqual(t, ResourceNotFoundError, err)
+
+	_, err = reader.GetResource(ResourceScope{Project: "..", Stage: "dev"}, "slo.yaml")
+	assert.NotNil(t, err)
+
+	resources, err := reader.GetAllResources(ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"})
+	require.Nil(t, err)
+	require.Len(t, resources, 2)
+	assert.Equal(t, "helm/values.yaml", *resources[0].ResourceURI)
+	assert.Equal(t, "replicas: 1", resources[0].ResourceContent)
+	assert.Equal(t, "slo.yaml", *resources[1].ResourceURI)
+
+	resources, err = reader.GetAllResources(ResourceScope{Project: "sockshop", Stage: "production"})
+	require.Nil(t, err)
+	assert.Empty(t, resources)
+}
+
+func TestLocalResourceReaderPutResource(t *testing.T) {
+	rootDir := t.TempDir()
+	reader := NewLocalResourceReader(rootDir)
+	scope := ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"}
+	uri := "/helm/values.yaml"
+
+	_, err := reader.PutResource(scope, &models.Resource{ResourceURI: &uri, ResourceContent: "replicas: 1"})
+	require.Nil(t, err)
+	_, err = reader.PutResource(scope, &models.Resource{ResourceURI: &uri, ResourceContent: "replicas: 2"})
+	require.Nil(t, err)
+
+	resource, err := NewResourceResolver(reader).ResolveResource(scope, "helm/values.yaml")
+	require.Nil(t, err)
+	assert.Equal(t, scope, resource.Scope)
+	assert.Equal(t, "replicas: 2", resource.Resource.ResourceContent)
+
+	invalidURI := "../../../resource/shipyard.yaml"
+	_, err = reader.PutResource(scope, &models.Resource{ResourceURI: &invalidURI, ResourceContent: "kind: Shipyard"})
+	assert.NotNil(t, err)
+	_, err = reader.PutResource(scope, &models.Resource{})
+	assert.NotNil(t, err)
+}
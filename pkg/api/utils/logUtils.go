@@ -180,7 +180,7 @@ func (lh *LogHandler) DeleteLogs(params models.LogFilter) error {
 	if params.BeforeTime != "" {
 		query.Set("beforeTime", params.BeforeTime)
 	}
-	if _, err := delete(u.String(), lh); err != nil {
+	if _, err := deleteRequest(u.String(), lh); err != nil {
 		return errors.New(err.GetMessage())
 	}
 	return nil
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+)
+
+// MemoryResourceStore is an in-memory implementation of the ResourceReader, which is mainly intended to be used in tests
+type MemoryResourceStore struct {
+	lock      sync.RWMutex
+	resources map[ResourceScope]map[string]string
+}
+
+// NewMemoryResourceStore creates a new, empty MemoryResourceStore
+func NewMemoryResourceStore() *MemoryResourceStore {
+	return &MemoryResourceStore{
+		resources: map[ResourceScope]map[string]string{},
+	}
+}
+
+// SetResource stores the content of the resource with the given URI
+func (m *MemoryResourceStore) SetResource(scope ResourceScope, resourceURI string, content string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.resources[scope] == nil {
+		m.resources[scope] = map[string]string{}
+	}
+	m.resources[scope][resourceURI] = content
+}
+
+// GetResource returns the resource with the given URI or ResourceNotFoundError if it does not exist
+func (m *MemoryResourceStore) GetResource(scope ResourceScope, resourceURI string) (*models.Resource, error) {
+	if err := scope.Validate(); err != nil {
+		return nil, err
+	}
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	content, ok := m.resources[scope][resourceURI]
+	if !ok {
+		return nil, ResourceNotFoundError
+	}
+	uri := resourceURI
+	return &models.Resource{ResourceURI: &uri, ResourceContent: content}, nil
+}
+
+// GetAllResources returns all resources of the scope, ordered by their resource URI
+func (m *MemoryResourceStore) GetAllResources(scope ResourceScope) ([]*models.Resource, error) {
+	if err := scope.Validate(); err != nil {
+		return nil, err
+	}
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	resources := []*models.Resource{}
+	for uri, content := range m.resources[scope] {
+		resourceURI := uri
+		resources = append(resources, &models.Resource{ResourceURI: &resourceURI, ResourceContent: content})
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return *resources[i].ResourceURI < *resources[j].ResourceURI
+	})
+	return resources, nil
+}
+
+// DeleteResource removes the resource with the given URI
+func (m *MemoryResourceStore) DeleteResource(scope ResourceScope, resourceURI string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.resources[scope], resourceURI)
+}
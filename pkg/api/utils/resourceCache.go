@@ -0,0 +1,328 @@
+package api
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/keptn/go-utils/pkg/api/models"
+)
+
+const defaultResourceCacheNegativeTTL = 10 * time.Second
+
+// ConditionalResourceReader is implemented by ResourceReaders which are able to only return a resource if it has been modified
+type ConditionalResourceReader interface {
+	// GetResourceIfModified returns the resource and its ETag if its ETag does not match the given one.
+	// If the resource has not been modified, ErrResourceNotModified is returned
+	GetResourceIfModified(scope ResourceScope, resourceURI string, etag string) (*models.Resource, string, error)
+}
+
+// CachedResourceReader is a ResourceReader which caches the results of another ResourceReader,
+// including the information that a resource does not exist.
+// If the underlying reader implements ConditionalResourceReader, expired resources are revalidated using their ETag
+type CachedResourceReader struct {
+	reader      ResourceReader
+	clock       clock.Clock
+	ttl         time.Duration
+	negativeTTL time.Duration
+	maxEntries  int
+	maxBytes    int
+
+	lock      sync.Mutex
+	resources map[resourceCacheKey]*list.Element
+	lists     map[ResourceScope]*list.Element
+	// lru contains the cached resources and resource lists, the most recently used first
+	lru  *list.List
+	size int
+}
+
+type resourceCacheKey struct {
+	scope       ResourceScope
+	resourceURI string
+}
+
+type cachedResource struct {
+	key       resourceCacheKey
+	resource  *models.Resource
+	etag      string
+	err       error
+	fetchedAt time.Time
+}
+
+type cachedResourceList struct {
+	scope     ResourceScope
+	resources []*models.Resource
+	fetchedAt time.Time
+}
+
+// CachedResourceReaderOption can be used to configure a CachedResourceReader
+type CachedResourceReaderOption func(*CachedResourceReader)
+
+// WithResourceCacheTTL sets the duration after which cached resources and resource lists expire.
+// By default, cached entries do not expire
+func WithResourceCacheTTL(ttl time.Duration) CachedResourceReaderOption {
+	return func(c *CachedResourceReader) {
+		c.ttl = ttl
+	}
+}
+
+// WithResourceCacheNegativeTTL sets the duration after which the information that a resource does not exist expires.
+// It defaults to 10 seconds and is limited by the TTL of the cache. A negative TTL of 0 disables caching of missing resources
+func WithResourceCacheNegativeTTL(ttl time.Duration) CachedResourceReaderOption {
+	return func(c *CachedResourceReader) {
+		c.negativeTTL = ttl
+	}
+}
+
+// WithResourceCacheMaxEntries limits the number of cached resources and resource lists. If the limit is reached,
+// the least recently used entries are evicted
+func WithResourceCacheMaxEntries(maxEntries int) CachedResourceReaderOption {
+	return func(c *CachedResourceReader) {
+		c.maxEntries = maxEntries
+	}
+}
+
+// WithResourceCacheMaxBytes limits the total size of the content of cached resources and resource lists. If the limit
+// is reached, the least recently used entries are evicted. Entries exceeding the limit on their own are not cached
+func WithResourceCacheMaxBytes(maxBytes int) CachedResourceReaderOption {
+	return func(c *CachedResourceReader) {
+		c.maxBytes = maxBytes
+	}
+}
+
+// WithResourceCacheClock sets the clock used to determine whether cached entries have expired
+func WithResourceCacheClock(clock clock.Clock) CachedResourceReaderOption {
+	return func(c *CachedResourceReader) {
+		c.clock = clock
+	}
+}
+
+// NewCachedResourceReader creates a new CachedResourceReader which caches the results of the given reader
+func NewCachedResourceReader(reader ResourceReader, opts ...CachedResourceReaderOption) *CachedResourceReader {
+	c := &CachedResourceReader{
+		reader:      reader,
+		clock:       clock.New(),
+		negativeTTL: defaultResourceCacheNegativeTTL,
+		resources:   map[resourceCacheKey]*list.Element{},
+		lru:         list.New(),
+		lists:       map[ResourceScope]*list.Element{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.ttl > 0 && c.negativeTTL > c.ttl {
+		c.negativeTTL = c.ttl
+	}
+	return c
+}
+
+// GetResource returns the cached resource or retrieves it from the underlying reader
+func (c *CachedResourceReader) GetResource(scope ResourceScope, resourceURI string) (*models.Resource, error) {
+	key := resourceCacheKey{scope: scope, resourceURI: resourceURI}
+	c.lock.Lock()
+	var cached *cachedResource
+	if element, ok := c.resources[key]; ok {
+		c.lru.MoveToFront(element)
+		cached = element.Value.(*cachedResource)
+		if !c.isExpired(cached) {
+			c.lock.Unlock()
+			return copyResource(cached.resource), cached.err
+		}
+	}
+	c.lock.Unlock()
+
+	etag := ""
+	if cached != nil && cached.err == nil {
+		etag = cached.etag
+	}
+	resource, newETag, err := c.fetch(scope, resourceURI, etag)
+	if err == ErrResourceNotModified && cached != nil {
+		c.store(&cachedResource{key: key, resource: cached.resource, etag: etag, fetchedAt: c.clock.Now()})
+		return copyResource(cached.resource), nil
+	}
+	return c.storeResult(key, resource, newETag, err)
+}
+
+// fetch retrieves the resource from the underlying reader, using a conditional request if an ETag is given and supported
+func (c *CachedResourceReader) fetch(scope ResourceScope, resourceURI string, etag string) (*models.Resource, string, error) {
+	if conditionalReader, ok := c.reader.(ConditionalResourceReader); ok {
+		return conditionalReader.GetResourceIfModified(scope, resourceURI, etag)
+	}
+	resource, err := c.reader.GetResource(scope, resourceURI)
+	return resource, "", err
+}
+
+func (c *CachedResourceReader) storeResult(key resourceCacheKey, resource *models.Resource, etag string, err error) (*models.Resource, error) {
+	// only the absence of a resource is cached, other errors may be temporary
+	if err == nil || (err == ResourceNotFoundError && c.negativeTTL > 0) {
+		c.store(&cachedResource{key: key, resource: copyResource(resource), etag: etag, err: err, fetchedAt: c.clock.Now()})
+	} else {
+		c.InvalidateResource(key.scope, key.resourceURI)
+	}
+	return resource, err
+}
+
+func (c *CachedResourceReader) isExpired(cached *cachedResource) bool {
+	ttl := c.ttl
+	if cached.err != nil {
+		ttl = c.negativeTTL
+	}
+	return ttl > 0 && c.clock.Now().Sub(cached.fetchedAt) >= ttl
+}
+
+// store adds the entry to the cache and evicts the least recently used entries if the cache exceeds its bounds
+func (c *CachedResourceReader) store(entry *cachedResource) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.removeResource(entry.key)
+
+	entrySize := resourceSize(entry.resource)
+	if c.maxBytes > 0 && entrySize > c.maxBytes {
+		return
+	}
+	c.resources[entry.key] = c.lru.PushFront(entry)
+	c.size += entrySize
+	c.evict()
+}
+
+// storeList adds the resource list to the cache and evicts the least recently used entries if the cache exceeds its bounds
+func (c *CachedResourceReader) storeList(entry *cachedResourceList) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.removeList(entry.scope)
+
+	entrySize := resourceListSize(entry.resources)
+	if c.maxBytes > 0 && entrySize > c.maxBytes {
+		return
+	}
+	c.lists[entry.scope] = c.lru.PushFront(entry)
+	c.size += entrySize
+	c.evict()
+}
+
+// evict removes the least recently used entries until the cache is within its bounds. The caller has to hold the lock
+func (c *CachedResourceReader) evict() {
+	for (c.maxEntries > 0 && c.lru.Len() > c.maxEntries) || (c.maxBytes > 0 && c.size > c.maxBytes) {
+		switch oldest := c.lru.Back().Value.(type) {
+		case *cachedResource:
+			c.removeResource(oldest.key)
+		case *cachedResourceList:
+			c.removeList(oldest.scope)
+		}
+	}
+}
+
+// removeResource removes the cached resource with the given key. The caller has to hold the lock
+func (c *CachedResourceReader) removeResource(key resourceCacheKey) {
+	element, ok := c.resources[key]
+	if !ok {
+		return
+	}
+	c.size -= resourceSize(element.Value.(*cachedResource).resource)
+	c.lru.Remove(element)
+	delete(c.resources, key)
+}
+
+// removeList removes the cached resource list of the given scope. The caller has to hold the lock
+func (c *CachedResourceReader) removeList(scope ResourceScope) {
+	element, ok := c.lists[scope]
+	if !ok {
+		return
+	}
+	c.size -= resourceListSize(element.Value.(*cachedResourceList).resources)
+	c.lru.Remove(element)
+	delete(c.lists, scope)
+}
+
+// GetAllResources returns the cached list of resources or retrieves it from the underlying reader
+func (c *CachedResourceReader) GetAllResources(scope ResourceScope) ([]*models.Resource, error) {
+	c.lock.Lock()
+	if element, ok := c.lists[scope]; ok {
+		cached := element.Value.(*cachedResourceList)
+		if c.ttl <= 0 || c.clock.Now().Sub(cached.fetchedAt) < c.ttl {
+			c.lru.MoveToFront(element)
+			c.lock.Unlock()
+			return copyResources(cached.resources), nil
+		}
+	}
+	c.lock.Unlock()
+
+	resources, err := c.reader.GetAllResources(scope)
+	if err != nil {
+		return nil, err
+	}
+	c.storeList(&cachedResourceList{scope: scope, resources: copyResources(resources), fetchedAt: c.clock.Now()})
+	return resources, nil
+}
+
+// Len returns the number of cached resources
+func (c *CachedResourceReader) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.resources)
+}
+
+// InvalidateResource removes the cached resource with the given URI of the given scope
+func (c *CachedResourceReader) InvalidateResource(scope ResourceScope, resourceURI string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.removeResource(resourceCacheKey{scope: scope, resourceURI: resourceURI})
+}
+
+// Invalidate removes all cached resources and resource lists of the given scope
+func (c *CachedResourceReader) Invalidate(scope ResourceScope) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for key := range c.resources {
+		if key.scope == scope {
+			c.removeResource(key)
+		}
+	}
+	c.removeList(scope)
+}
+
+// InvalidateAll removes all cached resources and resource lists
+func (c *CachedResourceReader) InvalidateAll() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.resources = map[resourceCacheKey]*list.Element{}
+	c.lru.Init()
+	c.size = 0
+	c.lists = map[ResourceScope]*list.Element{}
+}
+
+func resourceSize(resource *models.Resource) int {
+	if resource == nil {
+		return 0
+	}
+	return len(resource.ResourceContent)
+}
+
+func resourceListSize(resources []*models.Resource) int {
+	size := 0
+	for _, resource := range resources {
+		size += resourceSize(resource)
+	}
+	return size
+}
+
+func copyResource(resource *models.Resource) *models.Resource {
+	if resource == nil {
+		return nil
+	}
+	copied := *resource
+	if resource.Metadata != nil {
+		metadata := *resource.Metadata
+		copied.Metadata = &metadata
+	}
+	return &copied
+}
+
+func copyResources(resources []*models.Resource) []*models.Resource {
+	copied := make([]*models.Resource, len(resources))
+	for i, resource := range resources {
+		copied[i] = copyResource(resource)
+	}
+	return copied
+}
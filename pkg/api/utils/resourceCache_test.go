@@ -0,0 +1,231 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ ResourceReader = &CachedResourceReader{}
+var _ ConditionalResourceReader = &ResourceHandler{}
+
+func TestCachedResourceReader(t *testing.T) {
+	store := newTestResourceStore()
+	counter := &countingResourceReader{ResourceReader: store}
+	cache := NewCachedResourceReader(counter)
+
+	for i := 0; i < 3; i++ {
+		resource, err := cache.GetResource(stageScope, "slo.yaml")
+		require.Nil(t, err)
+		assert.Equal(t, "stage-slo", resource.ResourceContent)
+
+		_, err = cache.GetResource(stageScope, "missing.yaml")
+		assert.Equal(t, ResourceNotFoundError, err)
+
+		resources, err := cache.GetAllResources(stageScope)
+		require.Nil(t, err)
+		assert.Len(t, resources, 2)
+	}
+	assert.Equal(t, 2, counter.gets)
+	assert.Equal(t, 1, counter.lists)
+
+	// modifying a returned resource must not modify the cache
+	resource, _ := cache.GetResource(stageScope, "slo.yaml")
+	resource.ResourceContent = "modified"
+
+	store.SetResource(stageScope, "slo.yaml", "updated-slo")
+	resource, _ = cache.GetResource(stageScope, "slo.yaml")
+	assert.Equal(t, "stage-slo", resource.ResourceContent)
+
+	cache.Invalidate(stageScope)
+	resource, err := cache.GetResource(stageScope, "slo.yaml")
+	require.Nil(t, err)
+	assert.Equal(t, "updated-slo", resource.ResourceContent)
+	assert.Equal(t, 3, counter.gets)
+
+	cache.InvalidateAll()
+	_, _ = cache.GetAllResources(stageScope)
+	assert.Equal(t, 2, counter.lists)
+}
+
+func TestCachedResourceReaderTTL(t *testing.T) {
+	store := newTestResourceStore()
+	counter := &countingResourceReader{ResourceReader: store}
+	mockClock := clock.NewMock()
+	cache := NewCachedResourceReader(counter,
+		WithResourceCacheClock(mockClock),
+		WithResourceCacheTTL(time.Minute),
+		WithResourceCacheNegativeTTL(5*time.Second),
+	)
+
+	_, _ = cache.GetResource(stageScope, "slo.yaml")
+	_, _ = cache.GetResource(stageScope, "missing.yaml")
+	_, _ = cache.GetAllResources(stageScope)
+	assert.Equal(t, 2, counter.gets)
+	assert.Equal(t, 1, counter.lists)
+
+	// the negative entry expires first
+	mockClock.Add(5 * time.Second)
+	_, _ = cache.GetResource(stageScope, "slo.yaml")
+	_, err := cache.GetResource(stageScope, "missing.yaml")
+	assert.Equal(t, ResourceNotFoundError, err)
+	assert.Equal(t, 3, counter.gets)
+
+	store.SetResource(stageScope, "slo.yaml", "updated-slo")
+	mockClock.Add(time.Minute)
+	resource, err := cache.GetResource(stageScope, "slo.yaml")
+	require.Nil(t, err)
+	assert.Equal(t, "updated-slo", resource.ResourceContent)
+	_, _ = cache.GetAllResources(stageScope)
+	assert.Equal(t, 4, counter.gets)
+	assert.Equal(t, 2, counter.lists)
+}
+
+func TestCachedResourceReaderWithoutNegativeCaching(t *testing.T) {
+	counter := &countingResourceReader{ResourceReader: newTestResourceStore()}
+	cache := NewCachedResourceReader(counter, WithResourceCacheNegativeTTL(0))
+
+	for i := 0; i < 3; i++ {
+		_, err := cache.GetResource(stageScope, "missing.yaml")
+		assert.Equal(t, ResourceNotFoundError, err)
+	}
+	assert.Equal(t, 3, counter.gets)
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestCachedResourceReaderBounds(t *testing.T) {
+	store := NewMemoryResourceStore()
+	store.SetResource(projectScope, "a.yaml", "aaaa")
+	store.SetResource(projectScope, "b.yaml", "bbbb")
+	store.SetResource(projectScope, "c.yaml", "cccc")
+	store.SetResource(projectScope, "large.yaml", "large content")
+
+	t.Run("max entries", func(t *testing.T) {
+		counter := &countingResourceReader{ResourceReader: store}
+		cache := NewCachedResourceReader(counter, WithResourceCacheMaxEntries(2))
+		_, _ = cache.GetResource(projectScope, "a.yaml")
+		_, _ = cache.GetResource(projectScope, "b.yaml")
+		// a.yaml is now the most recently used resource, hence b.yaml is evicted
+		_, _ = cache.GetResource(projectScope, "a.yaml")
+		_, _ = cache.GetResource(projectScope, "c.yaml")
+		assert.Equal(t, 2, cache.Len())
+		assert.Equal(t, 3, counter.gets)
+
+		_, _ = cache.GetResource(projectScope, "a.yaml")
+		assert.Equal(t, 3, counter.gets)
+		_, _ = cache.GetResource(projectScope, "b.yaml")
+		assert.Equal(t, 4, counter.gets)
+	})
+
+	t.Run("max bytes", func(t *testing.T) {
+		counter := &countingResourceReader{ResourceReader: store}
+		cache := NewCachedResourceReader(counter, WithResourceCacheMaxBytes(10))
+		_, _ = cache.GetResource(projectScope, "a.yaml")
+		_, _ = cache.GetResource(projectScope, "b.yaml")
+		_, _ = cache.GetResource(projectScope, "c.yaml")
+		assert.Equal(t, 2, cache.Len())
+
+		resource, err := cache.GetResource(projectScope, "large.yaml")
+		require.Nil(t, err)
+		assert.Equal(t, "large content", resource.ResourceContent)
+		assert.Equal(t, 2, cache.Len())
+		assert.Equal(t, 4, counter.gets)
+	})
+
+	t.Run("resource lists", func(t *testing.T) {
+		counter := &countingResourceReader{ResourceReader: store}
+		cache := NewCachedResourceReader(counter, WithResourceCacheMaxEntries(2))
+		_, _ = cache.GetAllResources(projectScope)
+		_, _ = cache.GetAllResources(projectScope)
+		assert.Equal(t, 1, counter.lists)
+		// the resource list is the least recently used entry, hence it is evicted
+		_, _ = cache.GetResource(projectScope, "a.yaml")
+		_, _ = cache.GetResource(projectScope, "b.yaml")
+		_, _ = cache.GetAllResources(projectScope)
+		assert.Equal(t, 2, counter.lists)
+		assert.Equal(t, 1, cache.Len())
+
+		// the resource list exceeds the limit on its own
+		cache = NewCachedResourceReader(counter, WithResourceCacheMaxBytes(10))
+		_, _ = cache.GetAllResources(projectScope)
+		_, _ = cache.GetAllResources(projectScope)
+		assert.Equal(t, 4, counter.lists)
+	})
+
+	t.Run("invalidate resource", func(t *testing.T) {
+		counter := &countingResourceReader{ResourceReader: store}
+		cache := NewCachedResourceReader(counter)
+		_, _ = cache.GetResource(projectScope, "a.yaml")
+		_, _ = cache.GetResource(projectScope, "b.yaml")
+		cache.InvalidateResource(projectScope, "a.yaml")
+		assert.Equal(t, 1, cache.Len())
+		_, _ = cache.GetResource(projectScope, "a.yaml")
+		_, _ = cache.GetResource(projectScope, "b.yaml")
+		assert.Equal(t, 3, counter.gets)
+	})
+}
+
+func TestCachedResourceReaderConditionalRequests(t *testing.T) {
+	var lock sync.Mutex
+	content := `{"resourceURI":"slo.yaml","resourceContent":"b2JqZWN0aXZlczogW10="}`
+	etag := `"v1"`
+	fullResponses := 0
+	notModifiedResponses := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		if r.Header.Get("If-None-Match") == etag {
+			notModifiedResponses++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fullResponses++
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(content))
+	}))
+	defer ts.Close()
+
+	mockClock := clock.NewMock()
+	cache := NewCachedResourceReader(NewResourceHandler(ts.URL), WithResourceCacheClock(mockClock), WithResourceCacheTTL(time.Minute))
+
+	for i := 0; i < 3; i++ {
+		resource, err := cache.GetResource(stageScope, "slo.yaml")
+		require.Nil(t, err)
+		assert.Equal(t, "objectives: []", resource.ResourceContent)
+		mockClock.Add(time.Minute)
+	}
+	assert.Equal(t, 1, fullResponses)
+	assert.Equal(t, 2, notModifiedResponses)
+
+	lock.Lock()
+	content = `{"resourceURI":"slo.yaml","resourceContent":"b2JqZWN0aXZlczogW3Jlc3BvbnNlX3RpbWVd"}`
+	etag = `"v2"`
+	lock.Unlock()
+
+	resource, err := cache.GetResource(stageScope, "slo.yaml")
+	require.Nil(t, err)
+	assert.Equal(t, "objectives: [response_time]", resource.ResourceContent)
+	assert.Equal(t, 2, fullResponses)
+}
+
+func TestCachedResourceReaderDoesNotCacheErrors(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	cache := NewCachedResourceReader(NewResourceHandler(ts.URL))
+	for i := 0; i < 2; i++ {
+		_, err := cache.GetResource(stageScope, "slo.yaml")
+		assert.NotNil(t, err)
+	}
+	assert.Equal(t, 2, requests)
+}
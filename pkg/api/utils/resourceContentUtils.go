@@ -0,0 +1,138 @@
+package api
+
+import (
+	b64 "encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+)
+
+// DefaultMaxResourceContentSize is the default limit for the size of resource content read or written as bytes
+const DefaultMaxResourceContentSize = 64 << 20
+
+// maxResourceResponseOverhead is the size reserved for the parts of a resource response besides the encoded content
+const maxResourceResponseOverhead = 64 << 10
+
+// ErrResourceContentTooLarge is returned if the content of a resource exceeds the size limit
+var ErrResourceContentTooLarge = errors.New("resource content exceeds the size limit")
+
+// The configuration service transfers the content of resources base64 encoded. The string based methods of the
+// ResourceHandler encode and decode the content, i.e. the ResourceContent of a models.Resource passed to or returned
+// by them is always the plain content. The following methods provide the same for binary content such as helm charts,
+// without the need to convert the content to and from strings.
+
+// GetResourceContent retrieves the decoded content of a resource of the given scope.
+// If the content exceeds the size limit of the ResourceHandler, ErrResourceContentTooLarge is returned
+func (r *ResourceHandler) GetResourceContent(scope ResourceScope, resourceURI string) ([]byte, error) {
+	resourceURL, err := r.getResourceURL(scope, resourceURI)
+	if err != nil {
+		return nil, err
+	}
+	disableDefaultTransportTLSVerification()
+	req, err := http.NewRequest("GET", resourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	addAuthHeader(req, r)
+
+	resp, err := r.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	maxSize := r.getMaxContentSize()
+	maxResponseSize := int64(b64.StdEncoding.EncodedLen(int(maxSize))) + maxResourceResponseOverhead
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ResourceNotFoundError
+	}
+	if !(resp.StatusCode >= 200 && resp.StatusCode < 300) {
+		return nil, errors.New(string(body))
+	}
+	if int64(len(body)) > maxResponseSize {
+		return nil, ErrResourceContentTooLarge
+	}
+
+	var resource models.Resource
+	if err := json.Unmarshal(body, &resource); err != nil {
+		return nil, err
+	}
+	content, err := b64.StdEncoding.DecodeString(resource.ResourceContent)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode content of resource %s: %w", resourceURI, err)
+	}
+	if int64(len(content)) > maxSize {
+		return nil, ErrResourceContentTooLarge
+	}
+	return content, nil
+}
+
+// CreateResourceContent creates a resource of the given scope with the content read from the given reader and returns
+// the resulting version. A byte slice can be passed using bytes.NewReader.
+// If the content exceeds the size limit of the ResourceHandler, ErrResourceContentTooLarge is returned
+func (r *ResourceHandler) CreateResourceContent(scope ResourceScope, resourceURI string, content io.Reader) (string, error) {
+	if err := scope.Validate(); err != nil {
+		return "", err
+	}
+	if err := ValidateResourceURI(resourceURI); err != nil {
+		return "", err
+	}
+	encoded, err := r.readEncodedContent(content)
+	if err != nil {
+		return "", err
+	}
+	uri := resourceURI
+	return r.sendEncodedResources(r.getScopeURL(scope)+"/resource", http.MethodPost, &resourceRequest{
+		Resources: []*models.Resource{{ResourceURI: &uri, ResourceContent: encoded}},
+	})
+}
+
+// UpdateResourceContent updates the resource of the given scope with the content read from the given reader and returns
+// the resulting version. A byte slice can be passed using bytes.NewReader.
+// If the content exceeds the size limit of the ResourceHandler, ErrResourceContentTooLarge is returned
+func (r *ResourceHandler) UpdateResourceContent(scope ResourceScope, resourceURI string, content io.Reader) (string, error) {
+	resourceURL, err := r.getResourceURL(scope, resourceURI)
+	if err != nil {
+		return "", err
+	}
+	encoded, err := r.readEncodedContent(content)
+	if err != nil {
+		return "", err
+	}
+	uri := resourceURI
+	return r.sendEncodedResources(resourceURL, http.MethodPut, &models.Resource{
+		ResourceURI:     &uri,
+		ResourceContent: encoded,
+	})
+}
+
+// readEncodedContent reads the content up to the size limit and returns it base64 encoded
+func (r *ResourceHandler) readEncodedContent(content io.Reader) (string, error) {
+	maxSize := r.getMaxContentSize()
+	data, err := ioutil.ReadAll(io.LimitReader(content, maxSize+1))
+	if err != nil {
+		return "", fmt.Errorf("could not read resource content: %w", err)
+	}
+	if int64(len(data)) > maxSize {
+		return "", ErrResourceContentTooLarge
+	}
+	return b64.StdEncoding.EncodeToString(data), nil
+}
+
+func (r *ResourceHandler) getMaxContentSize() int64 {
+	if r.MaxContentSize > 0 {
+		return r.MaxContentSize
+	}
+	return DefaultMaxResourceContentSize
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestChartArchive returns a gzipped tar archive like a packaged helm chart
+func newTestChartArchive(t *testing.T) []byte {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	chart := []byte("apiVersion: v2\nname: carts\nversion: 0.1.0\n")
+	require.Nil(t, tw.WriteHeader(&tar.Header{Name: "carts/Chart.yaml", Mode: 0644, Size: int64(len(chart))}))
+	_, err := tw.Write(chart)
+	require.Nil(t, err)
+	require.Nil(t, tw.Close())
+	require.Nil(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestResourceHandler_ResourceContentRoundTrip(t *testing.T) {
+	allBytes := make([]byte, 256)
+	for i := range allBytes {
+		allBytes[i] = byte(i)
+	}
+	randomBytes := make([]byte, 100000)
+	rand.New(rand.NewSource(42)).Read(randomBytes)
+
+	tests := []struct {
+		name    string
+		content []byte
+	}{
+		{name: "chart archive", content: newTestChartArchive(t)},
+		{name: "all byte values", content: allBytes},
+		{name: "invalid utf-8", content: []byte{0xff, 0xfe, 0xfd, 0x00, 0xc3}},
+		{name: "random bytes", content: randomBytes},
+		{name: "empty", content: []byte{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newFakeConfigurationService(t, map[string]map[string]string{})
+			defer ts.Close()
+			rh := NewResourceHandler(ts.URL)
+			scope := ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"}
+
+			version, err := rh.CreateResourceContent(scope, "helm/carts.tgz", bytes.NewReader(tt.content))
+			require.Nil(t, err)
+			assert.Equal(t, "commit-1", version)
+
+			content, err := rh.GetResourceContent(scope, "helm/carts.tgz")
+			require.Nil(t, err)
+			assert.True(t, bytes.Equal(tt.content, content))
+
+			// the string based api returns the same content
+			resource, err := rh.GetResource(scope, "helm/carts.tgz")
+			require.Nil(t, err)
+			assert.Equal(t, string(tt.content), resource.ResourceContent)
+
+			updated := append([]byte{0x1f, 0x8b}, tt.content...)
+			version, err = rh.UpdateResourceContent(scope, "helm/carts.tgz", bytes.NewReader(updated))
+			require.Nil(t, err)
+			assert.Equal(t, "commit-2", version)
+
+			content, err = rh.GetResourceContent(scope, "helm/carts.tgz")
+			require.Nil(t, err)
+			assert.True(t, bytes.Equal(updated, content))
+		})
+	}
+}
+
+func TestResourceHandler_ResourceContentSizeLimit(t *testing.T) {
+	ts := newFakeConfigurationService(t, map[string]map[string]string{
+		"/v1/project/sockshop": {
+			"large.bin": string(make([]byte, 2048)),
+		},
+	})
+	defer ts.Close()
+	rh := NewResourceHandler(ts.URL)
+	rh.MaxContentSize = 1024
+	scope := ResourceScope{Project: "sockshop"}
+
+	_, err := rh.CreateResourceContent(scope, "too-large.bin", bytes.NewReader(make([]byte, 1025)))
+	assert.Equal(t, ErrResourceContentTooLarge, err)
+	_, err = rh.UpdateResourceContent(scope, "too-large.bin", bytes.NewReader(make([]byte, 1025)))
+	assert.Equal(t, ErrResourceContentTooLarge, err)
+	assert.Equal(t, 0, ts.writes)
+
+	_, err = rh.CreateResourceContent(scope, "small.bin", bytes.NewReader(make([]byte, 1024)))
+	assert.Nil(t, err)
+
+	_, err = rh.GetResourceContent(scope, "large.bin")
+	assert.Equal(t, ErrResourceContentTooLarge, err)
+
+	_, err = rh.GetResourceContent(scope, "missing.bin")
+	assert.Equal(t, ResourceNotFoundError, err)
+}
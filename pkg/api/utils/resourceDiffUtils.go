@@ -0,0 +1,207 @@
+package api
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const defaultResourceDiffContextLines = 3
+
+// ResourceDiffOptions configures how the resources of two scopes are compared
+type ResourceDiffOptions struct {
+	// IgnorePatterns excludes resources from the comparison. The patterns are matched against the resource URIs without
+	// leading slash using path.Match, e.g. "helm/*/templates/*". Patterns without a slash are also matched against the
+	// file name of the resources, e.g. "*.tgz"
+	IgnorePatterns []string
+	// ContextLines is the number of unchanged lines shown around the changes of the unified diffs. Defaults to 3 if not set
+	ContextLines *int
+}
+
+// ModifiedResource is a resource whose content differs between two scopes
+type ModifiedResource struct {
+	ResourceURI string
+	// Binary is set if the content of the resource is not text, in which case no unified diff is provided
+	Binary bool
+	// Diff is the unified diff of the content of the resource
+	Diff string
+}
+
+// ResourceDiff contains the differences between the resources of two scopes.
+// Resource URIs are listed without leading slash and sorted
+type ResourceDiff struct {
+	From ResourceScope
+	To   ResourceScope
+	// Added contains the resources which only exist in the scope the resources are compared to
+	Added []string
+	// Removed contains the resources which only exist in the scope the resources are compared from
+	Removed []string
+	// Modified contains the resources whose content differs
+	Modified []ModifiedResource
+	// Unchanged contains the resources whose content is the same in both scopes
+	Unchanged []string
+}
+
+// HasChanges returns true if any resource has been added, removed or modified
+func (d *ResourceDiff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Modified) > 0
+}
+
+// DiffResources compares the resources of two scopes, e.g. of a service in the stages "hardening" and "production",
+// and returns the added, removed and modified resources including unified diffs of their content
+func (r *ResourceHandler) DiffResources(from ResourceScope, to ResourceScope, opts ResourceDiffOptions) (*ResourceDiff, error) {
+	return diffResources(r, from, to, opts)
+}
+
+func diffResources(reader ResourceReader, from ResourceScope, to ResourceScope, opts ResourceDiffOptions) (*ResourceDiff, error) {
+	if err := from.Validate(); err != nil {
+		return nil, err
+	}
+	if err := to.Validate(); err != nil {
+		return nil, err
+	}
+	for _, pattern := range opts.IgnorePatterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid ignore pattern %s: %w", pattern, err)
+		}
+	}
+	contextLines := defaultResourceDiffContextLines
+	if opts.ContextLines != nil {
+		if *opts.ContextLines < 0 {
+			return nil, fmt.Errorf("context lines must not be negative: %d", *opts.ContextLines)
+		}
+		contextLines = *opts.ContextLines
+	}
+
+	fromURIs, err := listDiffableResources(reader, from, opts.IgnorePatterns)
+	if err != nil {
+		return nil, err
+	}
+	toURIs, err := listDiffableResources(reader, to, opts.IgnorePatterns)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &ResourceDiff{
+		From:      from,
+		To:        to,
+		Added:     []string{},
+		Removed:   []string{},
+		Modified:  []ModifiedResource{},
+		Unchanged: []string{},
+	}
+	for _, name := range sortedResourceNames(fromURIs, toURIs) {
+		fromURI, inFrom := fromURIs[name]
+		toURI, inTo := toURIs[name]
+		switch {
+		case !inFrom:
+			diff.Added = append(diff.Added, name)
+		case !inTo:
+			diff.Removed = append(diff.Removed, name)
+		default:
+			fromResource, err := reader.GetResource(from, fromURI)
+			if err != nil {
+				return nil, fmt.Errorf("could not retrieve resource %s of %s: %w", fromURI, from, err)
+			}
+			toResource, err := reader.GetResource(to, toURI)
+			if err != nil {
+				return nil, fmt.Errorf("could not retrieve resource %s of %s: %w", toURI, to, err)
+			}
+			if fromResource.ResourceContent == toResource.ResourceContent {
+				diff.Unchanged = append(diff.Unchanged, name)
+				continue
+			}
+			modified, err := diffResourceContent(name, from, to, fromResource.ResourceContent, toResource.ResourceContent, contextLines)
+			if err != nil {
+				return nil, err
+			}
+			diff.Modified = append(diff.Modified, *modified)
+		}
+	}
+	return diff, nil
+}
+
+// listDiffableResources returns the resources of the scope which are not ignored, mapped from their URI without leading
+// slash to their original URI
+func listDiffableResources(reader ResourceReader, scope ResourceScope, ignorePatterns []string) (map[string]string, error) {
+	resources, err := reader.GetAllResources(scope)
+	if err != nil {
+		return nil, fmt.Errorf("could not list resources of %s: %w", scope, err)
+	}
+	uris := map[string]string{}
+	for _, resource := range resources {
+		if resource.ResourceURI == nil {
+			continue
+		}
+		name := strings.TrimPrefix(*resource.ResourceURI, "/")
+		if !isIgnoredResource(name, ignorePatterns) {
+			uris[name] = *resource.ResourceURI
+		}
+	}
+	return uris, nil
+}
+
+func isIgnoredResource(name string, ignorePatterns []string) bool {
+	for _, pattern := range ignorePatterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+		if !strings.Contains(pattern, "/") {
+			if matched, _ := path.Match(pattern, path.Base(name)); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func sortedResourceNames(uris ...map[string]string) []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, m := range uris {
+		for name := range m {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func diffResourceContent(name string, from ResourceScope, to ResourceScope, fromContent string, toContent string, contextLines int) (*ModifiedResource, error) {
+	if !isTextContent(fromContent) || !isTextContent(toContent) {
+		return &ModifiedResource{ResourceURI: name, Binary: true}, nil
+	}
+	unifiedDiff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitContentLines(fromContent),
+		B:        splitContentLines(toContent),
+		FromFile: from.String() + ": " + name,
+		ToFile:   to.String() + ": " + name,
+		Context:  contextLines,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create diff of resource %s: %w", name, err)
+	}
+	return &ModifiedResource{ResourceURI: name, Diff: unifiedDiff}, nil
+}
+
+// splitContentLines splits the content into lines including their line breaks. Unlike difflib.SplitLines, no empty
+// line is added if the content ends with a line break
+func splitContentLines(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// isTextContent considers content as text if it is valid UTF-8 and does not contain NUL bytes
+func isTextContent(content string) bool {
+	return utf8.ValidString(content) && !strings.ContainsRune(content, 0)
+}
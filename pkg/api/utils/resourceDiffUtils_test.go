@@ -0,0 +1,133 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceHandler_DiffResources(t *testing.T) {
+	ts := newFakeConfigurationService(t, map[string]map[string]string{
+		"/v1/project/sockshop/stage/hardening/service/carts": {
+			"/helm/carts/values.yaml": "replicas: 1\nimage: carts:0.12.3\n",
+			"/helm/carts.tgz":         "\x1f\x8b\x08\x00new",
+			"/slo.yaml":               "objectives: []\n",
+			"/locust/load.py":         "print('load')\n",
+			"/jmeter/test.jmx":        "<jmeterTestPlan/>",
+		},
+		"/v1/project/sockshop/stage/production/service/carts": {
+			"helm/carts/values.yaml": "replicas: 3\nimage: carts:0.12.1\n",
+			"helm/carts.tgz":         "\x1f\x8b\x08\x00old",
+			"slo.yaml":               "objectives: []\n",
+			"remediation.yaml":       "remediations: []\n",
+		},
+	})
+	defer ts.Close()
+	rh := NewResourceHandler(ts.URL)
+
+	hardening := ResourceScope{Project: "sockshop", Stage: "hardening", Service: "carts"}
+	production := ResourceScope{Project: "sockshop", Stage: "production", Service: "carts"}
+	diff, err := rh.DiffResources(hardening, production, ResourceDiffOptions{IgnorePatterns: []string{"locust/*"}})
+	require.Nil(t, err)
+
+	assert.True(t, diff.HasChanges())
+	assert.Equal(t, []string{"remediation.yaml"}, diff.Added)
+	assert.Equal(t, []string{"jmeter/test.jmx"}, diff.Removed)
+	assert.Equal(t, []string{"slo.yaml"}, diff.Unchanged)
+	require.Len(t, diff.Modified, 2)
+
+	assert.Equal(t, "helm/carts.tgz", diff.Modified[0].ResourceURI)
+	assert.True(t, diff.Modified[0].Binary)
+	assert.Empty(t, diff.Modified[0].Diff)
+
+	assert.Equal(t, "helm/carts/values.yaml", diff.Modified[1].ResourceURI)
+	assert.False(t, diff.Modified[1].Binary)
+	assert.Equal(t, `--- project sockshop, stage hardening, service carts: helm/carts/values.yaml
++++ project sockshop, stage production, service carts: helm/carts/values.yaml
+@@ -1,2 +1,2 @@
+-replicas: 1
+-image: carts:0.12.3
++replicas: 3
++image: carts:0.12.1
+`, diff.Modified[1].Diff)
+}
+
+func TestResourceHandler_DiffResourcesContextLines(t *testing.T) {
+	ts := newFakeConfigurationService(t, map[string]map[string]string{
+		"/v1/project/sockshop/stage/hardening":  {"/values.yaml": "a: 1\nb: 1\nc: 1\n"},
+		"/v1/project/sockshop/stage/production": {"/values.yaml": "a: 1\nb: 2\nc: 1\n"},
+	})
+	defer ts.Close()
+	rh := NewResourceHandler(ts.URL)
+	hardening := ResourceScope{Project: "sockshop", Stage: "hardening"}
+	production := ResourceScope{Project: "sockshop", Stage: "production"}
+
+	contextLines := 0
+	diff, err := rh.DiffResources(hardening, production, ResourceDiffOptions{ContextLines: &contextLines})
+	require.Nil(t, err)
+	require.Len(t, diff.Modified, 1)
+	assert.Equal(t, `--- project sockshop, stage hardening: values.yaml
++++ project sockshop, stage production: values.yaml
+@@ -2 +2 @@
+-b: 1
++b: 2
+`, diff.Modified[0].Diff)
+
+	diff, err = rh.DiffResources(hardening, production, ResourceDiffOptions{})
+	require.Nil(t, err)
+	require.Len(t, diff.Modified, 1)
+	assert.Contains(t, diff.Modified[0].Diff, "@@ -1,3 +1,3 @@\n a: 1\n-b: 1\n+b: 2\n c: 1\n")
+
+	contextLines = -1
+	_, err = rh.DiffResources(hardening, production, ResourceDiffOptions{ContextLines: &contextLines})
+	assert.NotNil(t, err)
+}
+
+func TestResourceHandler_DiffResourcesIgnorePatterns(t *testing.T) {
+	ts := newFakeConfigurationService(t, map[string]map[string]string{
+		"/v1/project/sockshop/stage/hardening": {
+			"/helm/carts.tgz": "new",
+			"/slo.yaml":       "objectives: []\n",
+		},
+		"/v1/project/sockshop/stage/production": {
+			"/helm/carts.tgz": "old",
+			"/slo.yaml":       "objectives: []\n",
+		},
+	})
+	defer ts.Close()
+	rh := NewResourceHandler(ts.URL)
+
+	diff, err := rh.DiffResources(ResourceScope{Project: "sockshop", Stage: "hardening"}, ResourceScope{Project: "sockshop", Stage: "production"},
+		ResourceDiffOptions{IgnorePatterns: []string{"*.tgz"}})
+	require.Nil(t, err)
+	assert.False(t, diff.HasChanges())
+	assert.Equal(t, []string{"slo.yaml"}, diff.Unchanged)
+
+	_, err = rh.DiffResources(ResourceScope{Project: "sockshop", Stage: "hardening"}, ResourceScope{Project: "sockshop", Stage: "production"},
+		ResourceDiffOptions{IgnorePatterns: []string{"[invalid"}})
+	assert.NotNil(t, err)
+
+	_, err = rh.DiffResources(ResourceScope{Project: "sockshop", Service: "carts"}, ResourceScope{Project: "sockshop", Stage: "production"}, ResourceDiffOptions{})
+	assert.NotNil(t, err)
+}
+
+func Test_isIgnoredResource(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource string
+		patterns []string
+		want     bool
+	}{
+		{name: "no patterns", resource: "slo.yaml", want: false},
+		{name: "full path", resource: "helm/carts/values.yaml", patterns: []string{"helm/*/values.yaml"}, want: true},
+		{name: "file name", resource: "helm/carts.tgz", patterns: []string{"*.tgz"}, want: true},
+		{name: "pattern with slash only matches full path", resource: "helm/carts/values.yaml", patterns: []string{"carts/values.yaml"}, want: false},
+		{name: "no match", resource: "slo.yaml", patterns: []string{"*.tgz", "helm/*"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isIgnoredResource(tt.resource, tt.patterns))
+		})
+	}
+}
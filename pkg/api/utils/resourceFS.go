@@ -0,0 +1,315 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+)
+
+// ResourceReader is the api to read resources of a ResourceScope
+type ResourceReader interface {
+	// GetResource returns the resource with the given URI. If the resource does not exist, ResourceNotFoundError is returned
+	GetResource(scope ResourceScope, resourceURI string) (*models.Resource, error)
+	// GetAllResources returns all resources of the scope. The content of the returned resources is not necessarily set
+	GetAllResources(scope ResourceScope) ([]*models.Resource, error)
+}
+
+// ResourceProvider is the api to retrieve, list and store resources of a ResourceScope.
+// It is implemented by the ResourceHandler for the configuration service and by the LocalResourceReader for a local directory
+type ResourceProvider interface {
+	ResourceReader
+	// PutResource creates or updates the resource and returns the resulting version, if the provider supports versions
+	PutResource(scope ResourceScope, resource *models.Resource) (string, error)
+}
+
+// Hierarchy returns the scope followed by its parent scopes, ordered from the most specific to the least specific one,
+// e.g. the service, stage and project level for a scope referring to a service
+func (s ResourceScope) Hierarchy() []ResourceScope {
+	scopes := []ResourceScope{}
+	if s.IsServiceLevel() {
+		scopes = append(scopes, s)
+	}
+	if s.Stage != "" {
+		scopes = append(scopes, ResourceScope{Project: s.Project, Stage: s.Stage})
+	}
+	return append(scopes, ResourceScope{Project: s.Project})
+}
+
+// ResourceFS is a read-only fs.FS backed by the resources of a ResourceScope.
+// Resource URIs are mapped to slash-separated paths without a leading slash, e.g. the resource "/helm/values.yaml"
+// can be opened as "helm/values.yaml". Directories are derived from the resource URIs.
+type ResourceFS struct {
+	reader   ResourceReader
+	scope    ResourceScope
+	fallback bool
+}
+
+// ResourceFSOption can be used to configure a ResourceFS
+type ResourceFSOption func(*ResourceFS)
+
+// WithScopeFallback configures the ResourceFS to fall back to the stage and then to the project level for resources
+// that do not exist on the level of its scope
+func WithScopeFallback() ResourceFSOption {
+	return func(rfs *ResourceFS) {
+		rfs.fallback = true
+	}
+}
+
+// NewResourceFS creates a new ResourceFS for the resources of the given scope.
+// Consider wrapping the reader with a CachedResourceReader, since the resources are listed on every call
+func NewResourceFS(reader ResourceReader, scope ResourceScope, opts ...ResourceFSOption) *ResourceFS {
+	rfs := &ResourceFS{
+		reader: reader,
+		scope:  scope,
+	}
+	for _, opt := range opts {
+		opt(rfs)
+	}
+	return rfs
+}
+
+type resourceFSEntry struct {
+	scope       ResourceScope
+	resourceURI string
+}
+
+// index returns the files visible in the file system mapped to the scope and resource URI they are read from
+func (rfs *ResourceFS) index() (map[string]resourceFSEntry, error) {
+	if err := rfs.scope.Validate(); err != nil {
+		return nil, err
+	}
+	scopes := []ResourceScope{rfs.scope}
+	if rfs.fallback {
+		scopes = rfs.scope.Hierarchy()
+	}
+
+	files := map[string]resourceFSEntry{}
+	for _, scope := range scopes {
+		resources, err := rfs.reader.GetAllResources(scope)
+		if err != nil {
+			return nil, fmt.Errorf("could not list resources of %s: %s", scope, err.Error())
+		}
+		for _, resource := range resources {
+			if resource.ResourceURI == nil {
+				continue
+			}
+			name := path.Clean(strings.TrimPrefix(*resource.ResourceURI, "/"))
+			if !fs.ValidPath(name) || name == "." {
+				continue
+			}
+			// scopes are ordered from the most specific to the least specific one
+			if _, ok := files[name]; !ok {
+				files[name] = resourceFSEntry{scope: scope, resourceURI: *resource.ResourceURI}
+			}
+		}
+	}
+	return files, nil
+}
+
+// Open opens the named file or directory
+func (rfs *ResourceFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	files, err := rfs.index()
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	if entry, ok := files[name]; ok {
+		resource, err := rfs.reader.GetResource(entry.scope, entry.resourceURI)
+		if err != nil {
+			if errors.Is(err, ResourceNotFoundError) {
+				err = fs.ErrNotExist
+			}
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		return &resourceFile{
+			info:   resourceFileInfo{name: path.Base(name), size: int64(len(resource.ResourceContent))},
+			reader: bytes.NewReader([]byte(resource.ResourceContent)),
+		}, nil
+	}
+
+	entries := rfs.dirEntries(files, name)
+	if name != "." && len(entries) == 0 {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &resourceDir{
+		info:    resourceFileInfo{name: path.Base(name), dir: true},
+		entries: entries,
+	}, nil
+}
+
+// ReadFile reads the named file and returns its contents
+func (rfs *ResourceFS) ReadFile(name string) ([]byte, error) {
+	f, err := rfs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	file, ok := f.(*resourceFile)
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: errors.New("is a directory")}
+	}
+	return io.ReadAll(file.reader)
+}
+
+// ReadDir reads the named directory and returns its entries sorted by name
+func (rfs *ResourceFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	files, err := rfs.index()
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	if _, ok := files[name]; ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	entries := rfs.dirEntries(files, name)
+	if name != "." && len(entries) == 0 {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	return entries, nil
+}
+
+// dirEntries returns the entries of the named directory, sorted by name
+func (rfs *ResourceFS) dirEntries(files map[string]resourceFSEntry, dir string) []fs.DirEntry {
+	prefix := ""
+	if dir != "." {
+		prefix = dir + "/"
+	}
+	seen := map[string]bool{}
+	entries := []fs.DirEntry{}
+	for name := range files {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(name, prefix)
+		entry := &resourceDirEntry{name: rest, reader: rfs.reader, source: files[name]}
+		if idx := strings.Index(rest, "/"); idx >= 0 {
+			entry = &resourceDirEntry{name: rest[:idx], dir: true}
+		}
+		if seen[entry.name] {
+			continue
+		}
+		seen[entry.name] = true
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries
+}
+
+type resourceFileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i resourceFileInfo) Name() string { return i.name }
+
+func (i resourceFileInfo) Size() int64 { return i.size }
+
+func (i resourceFileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+func (i resourceFileInfo) ModTime() time.Time { return time.Time{} }
+
+func (i resourceFileInfo) IsDir() bool { return i.dir }
+
+func (i resourceFileInfo) Sys() interface{} { return nil }
+
+// resourceDirEntry is an entry of a directory. The size of files is only retrieved if Info is called
+type resourceDirEntry struct {
+	name   string
+	dir    bool
+	reader ResourceReader
+	source resourceFSEntry
+}
+
+func (e *resourceDirEntry) Name() string { return e.name }
+
+func (e *resourceDirEntry) IsDir() bool { return e.dir }
+
+func (e *resourceDirEntry) Type() fs.FileMode {
+	if e.dir {
+		return fs.ModeDir
+	}
+	return 0
+}
+
+func (e *resourceDirEntry) Info() (fs.FileInfo, error) {
+	if e.dir {
+		return resourceFileInfo{name: e.name, dir: true}, nil
+	}
+	resource, err := e.reader.GetResource(e.source.scope, e.source.resourceURI)
+	if err != nil {
+		if errors.Is(err, ResourceNotFoundError) {
+			err = fs.ErrNotExist
+		}
+		return nil, err
+	}
+	return resourceFileInfo{name: e.name, size: int64(len(resource.ResourceContent))}, nil
+}
+
+type resourceFile struct {
+	info   resourceFileInfo
+	reader *bytes.Reader
+}
+
+func (f *resourceFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+func (f *resourceFile) Read(b []byte) (int, error) { return f.reader.Read(b) }
+
+func (f *resourceFile) Seek(offset int64, whence int) (int64, error) {
+	return f.reader.Seek(offset, whence)
+}
+
+func (f *resourceFile) Close() error { return nil }
+
+type resourceDir struct {
+	info    resourceFileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *resourceDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+
+func (d *resourceDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: errors.New("is a directory")}
+}
+
+func (d *resourceDir) Close() error { return nil }
+
+// ReadDir reads the entries of the directory as specified by fs.ReadDirFile
+func (d *resourceDir) ReadDir(count int) ([]fs.DirEntry, error) {
+	remaining := len(d.entries) - d.offset
+	if count <= 0 {
+		entries := d.entries[d.offset:]
+		d.offset = len(d.entries)
+		return entries, nil
+	}
+	if remaining == 0 {
+		return nil, io.EOF
+	}
+	if count > remaining {
+		count = remaining
+	}
+	entries := d.entries[d.offset : d.offset+count]
+	d.offset += count
+	return entries, nil
+}
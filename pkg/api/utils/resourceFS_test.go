@@ -0,0 +1,149 @@
+package api
+
+import (
+	"bytes"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"text/template"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ ResourceReader = &ResourceHandler{}
+var _ ResourceReader = &MemoryResourceStore{}
+
+var _ fs.ReadFileFS = &ResourceFS{}
+var _ fs.ReadDirFS = &ResourceFS{}
+
+var projectScope = ResourceScope{Project: "sockshop"}
+var stageScope = ResourceScope{Project: "sockshop", Stage: "dev"}
+var serviceScope = ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"}
+
+func newTestResourceStore() *MemoryResourceStore {
+	store := NewMemoryResourceStore()
+	store.SetResource(projectScope, "shipyard.yaml", "kind: Shipyard")
+	store.SetResource(projectScope, "slo.yaml", "project-slo")
+	store.SetResource(stageScope, "slo.yaml", "stage-slo")
+	store.SetResource(stageScope, "templates/stage.tmpl", "stage {{.}}")
+	store.SetResource(serviceScope, "/helm/carts/values.yaml", "replicas: 1")
+	store.SetResource(serviceScope, "/helm/carts/templates/deployment.yaml", "kind: Deployment")
+	store.SetResource(serviceScope, "templates/service.tmpl", "service {{.}}")
+	return store
+}
+
+func TestResourceFS(t *testing.T) {
+	rfs := NewResourceFS(newTestResourceStore(), serviceScope)
+
+	err := fstest.TestFS(rfs, "helm/carts/values.yaml", "helm/carts/templates/deployment.yaml", "templates/service.tmpl")
+	require.Nil(t, err)
+
+	content, err := fs.ReadFile(rfs, "helm/carts/values.yaml")
+	require.Nil(t, err)
+	assert.Equal(t, "replicas: 1", string(content))
+
+	_, err = fs.ReadFile(rfs, "slo.yaml")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	_, err = rfs.Open("/helm/carts/values.yaml")
+	assert.ErrorIs(t, err, fs.ErrInvalid)
+
+	entries, err := fs.ReadDir(rfs, "helm/carts")
+	require.Nil(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "templates", entries[0].Name())
+	assert.True(t, entries[0].IsDir())
+	assert.Equal(t, "values.yaml", entries[1].Name())
+	assert.False(t, entries[1].IsDir())
+}
+
+func TestResourceFSWithScopeFallback(t *testing.T) {
+	rfs := NewResourceFS(newTestResourceStore(), serviceScope, WithScopeFallback())
+
+	err := fstest.TestFS(rfs, "helm/carts/values.yaml", "slo.yaml", "shipyard.yaml", "templates/stage.tmpl", "templates/service.tmpl")
+	require.Nil(t, err)
+
+	content, err := fs.ReadFile(rfs, "slo.yaml")
+	require.Nil(t, err)
+	assert.Equal(t, "stage-slo", string(content))
+
+	content, err = fs.ReadFile(rfs, "shipyard.yaml")
+	require.Nil(t, err)
+	assert.Equal(t, "kind: Shipyard", string(content))
+
+	files := []string{}
+	err = fs.WalkDir(rfs, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	require.Nil(t, err)
+	assert.Equal(t, []string{
+		"helm/carts/templates/deployment.yaml",
+		"helm/carts/values.yaml",
+		"shipyard.yaml",
+		"slo.yaml",
+		"templates/service.tmpl",
+		"templates/stage.tmpl",
+	}, files)
+
+	tmpl, err := template.ParseFS(rfs, "templates/*.tmpl")
+	require.Nil(t, err)
+	buf := &bytes.Buffer{}
+	require.Nil(t, tmpl.ExecuteTemplate(buf, "stage.tmpl", "dev"))
+	assert.Equal(t, "stage dev", buf.String())
+}
+
+func TestResourceFSInvalidScope(t *testing.T) {
+	rfs := NewResourceFS(newTestResourceStore(), ResourceScope{Stage: "dev"})
+	_, err := fs.ReadFile(rfs, "slo.yaml")
+	assert.NotNil(t, err)
+}
+
+type countingResourceReader struct {
+	ResourceReader
+	gets  int
+	lists int
+}
+
+func (c *countingResourceReader) GetResource(scope ResourceScope, resourceURI string) (*models.Resource, error) {
+	c.gets++
+	return c.ResourceReader.GetResource(scope, resourceURI)
+}
+
+func (c *countingResourceReader) GetAllResources(scope ResourceScope) ([]*models.Resource, error) {
+	c.lists++
+	return c.ResourceReader.GetAllResources(scope)
+}
+
+func TestResourceFSWithCachedResourceReader(t *testing.T) {
+	counter := &countingResourceReader{ResourceReader: newTestResourceStore()}
+	rfs := NewResourceFS(NewCachedResourceReader(counter), serviceScope, WithScopeFallback())
+
+	for i := 0; i < 3; i++ {
+		_, err := fs.ReadFile(rfs, "slo.yaml")
+		require.Nil(t, err)
+	}
+	assert.Equal(t, 1, counter.gets)
+	assert.Equal(t, 3, counter.lists)
+}
+
+func TestResourceHandlerAsResourceFS(t *testing.T) {
+	ts := newFakeConfigurationService(t, map[string]map[string]string{
+		"/v1/project/sockshop/stage/dev/service/carts": {
+			"/helm/carts/values.yaml": "replicas: 1",
+		},
+	})
+	defer ts.Close()
+
+	rfs := NewResourceFS(NewResourceHandler(ts.URL), serviceScope)
+	content, err := fs.ReadFile(rfs, "helm/carts/values.yaml")
+	require.Nil(t, err)
+	assert.Equal(t, "replicas: 1", string(content))
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+)
+
+// ScopedResource is a resource together with the scope it has been retrieved from
+type ScopedResource struct {
+	Scope    ResourceScope
+	Resource *models.Resource
+}
+
+// ResourceResolver resolves resources across the levels of a scope using any ResourceReader, the same way as the
+// ResourceHandler does for the configuration service
+type ResourceResolver struct {
+	reader ResourceReader
+}
+
+// NewResourceResolver creates a new ResourceResolver reading the resources from the given reader
+func NewResourceResolver(reader ResourceReader) *ResourceResolver {
+	return &ResourceResolver{reader: reader}
+}
+
+// ResolveResource retrieves the resource with the given URI from the most specific level of the scope it is available on.
+// See ResourceHandler.ResolveResource
+func (r *ResourceResolver) ResolveResource(scope ResourceScope, resourceURI string) (*ScopedResource, error) {
+	return resolveResource(r.reader, scope, resourceURI)
+}
+
+// GetResourceFromAllLevels retrieves the resource with the given URI from all levels of the scope it is available on.
+// See ResourceHandler.GetResourceFromAllLevels
+func (r *ResourceResolver) GetResourceFromAllLevels(scope ResourceScope, resourceURI string) ([]*ScopedResource, error) {
+	return getResourceFromAllLevels(r.reader, scope, resourceURI)
+}
+
+// ResolveResource retrieves the resource with the given URI from the most specific level of the scope it is available on.
+// For a scope referring to a service, the service level is checked first, followed by the stage and the project level.
+// If the resource is not available on any level, ResourceNotFoundError is returned
+func (r *ResourceHandler) ResolveResource(scope ResourceScope, resourceURI string) (*ScopedResource, error) {
+	return resolveResource(r, scope, resourceURI)
+}
+
+// GetResourceFromAllLevels retrieves the resource with the given URI from all levels of the scope it is available on.
+// The resources are ordered from the least specific to the most specific level, i.e. project, stage and service,
+// so that callers can merge them by letting later resources override earlier ones.
+// If the resource is not available on any level, an empty list is returned
+func (r *ResourceHandler) GetResourceFromAllLevels(scope ResourceScope, resourceURI string) ([]*ScopedResource, error) {
+	return getResourceFromAllLevels(r, scope, resourceURI)
+}
+
+func resolveResource(reader ResourceReader, scope ResourceScope, resourceURI string) (*ScopedResource, error) {
+	if err := scope.Validate(); err != nil {
+		return nil, err
+	}
+	for _, level := range scope.Hierarchy() {
+		resource, err := reader.GetResource(level, resourceURI)
+		if err == ResourceNotFoundError {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve resource %s of %s: %w", resourceURI, level, err)
+		}
+		return &ScopedResource{Scope: level, Resource: resource}, nil
+	}
+	return nil, ResourceNotFoundError
+}
+
+func getResourceFromAllLevels(reader ResourceReader, scope ResourceScope, resourceURI string) ([]*ScopedResource, error) {
+	if err := scope.Validate(); err != nil {
+		return nil, err
+	}
+	levels := scope.Hierarchy()
+	resources := []*ScopedResource{}
+	for i := len(levels) - 1; i >= 0; i-- {
+		resource, err := reader.GetResource(levels[i], resourceURI)
+		if err == ResourceNotFoundError {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve resource %s of %s: %w", resourceURI, levels[i], err)
+		}
+		resources = append(resources, &ScopedResource{Scope: levels[i], Resource: resource})
+	}
+	return resources, nil
+}
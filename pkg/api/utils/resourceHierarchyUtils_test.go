@@ -0,0 +1,121 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newHierarchyTestService(t *testing.T) *fakeConfigurationService {
+	return newFakeConfigurationService(t, map[string]map[string]string{
+		"/v1/project/sockshop": {
+			"slo.yaml":      "project-slo",
+			"shipyard.yaml": "kind: Shipyard",
+		},
+		"/v1/project/sockshop/stage/dev": {
+			"slo.yaml": "stage-slo",
+		},
+		"/v1/project/sockshop/stage/dev/service/carts": {
+			"helm/values.yaml": "replicas: 1",
+		},
+	})
+}
+
+func TestResourceHandler_ResolveResource(t *testing.T) {
+	ts := newHierarchyTestService(t)
+	defer ts.Close()
+	rh := NewResourceHandler(ts.URL)
+
+	tests := []struct {
+		name        string
+		scope       ResourceScope
+		resourceURI string
+		wantScope   ResourceScope
+		wantContent string
+		wantErr     error
+	}{
+		{
+			name:        "service level",
+			scope:       ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"},
+			resourceURI: "helm/values.yaml",
+			wantScope:   ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"},
+			wantContent: "replicas: 1",
+		},
+		{
+			name:        "fallback to stage level",
+			scope:       ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"},
+			resourceURI: "slo.yaml",
+			wantScope:   ResourceScope{Project: "sockshop", Stage: "dev"},
+			wantContent: "stage-slo",
+		},
+		{
+			name:        "fallback to project level",
+			scope:       ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"},
+			resourceURI: "shipyard.yaml",
+			wantScope:   ResourceScope{Project: "sockshop"},
+			wantContent: "kind: Shipyard",
+		},
+		{
+			name:        "stage scope",
+			scope:       ResourceScope{Project: "sockshop", Stage: "dev"},
+			resourceURI: "slo.yaml",
+			wantScope:   ResourceScope{Project: "sockshop", Stage: "dev"},
+			wantContent: "stage-slo",
+		},
+		{
+			name:        "not found",
+			scope:       ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"},
+			resourceURI: "missing.yaml",
+			wantErr:     ResourceNotFoundError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rh.ResolveResource(tt.scope, tt.resourceURI)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.wantScope, got.Scope)
+			assert.Equal(t, tt.wantContent, got.Resource.ResourceContent)
+		})
+	}
+}
+
+func TestResourceHandler_GetResourceFromAllLevels(t *testing.T) {
+	ts := newHierarchyTestService(t)
+	defer ts.Close()
+	rh := NewResourceHandler(ts.URL)
+
+	resources, err := rh.GetResourceFromAllLevels(ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"}, "slo.yaml")
+	require.Nil(t, err)
+	require.Len(t, resources, 2)
+	assert.Equal(t, ResourceScope{Project: "sockshop"}, resources[0].Scope)
+	assert.Equal(t, "project-slo", resources[0].Resource.ResourceContent)
+	assert.Equal(t, ResourceScope{Project: "sockshop", Stage: "dev"}, resources[1].Scope)
+	assert.Equal(t, "stage-slo", resources[1].Resource.ResourceContent)
+
+	resources, err = rh.GetResourceFromAllLevels(ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"}, "missing.yaml")
+	require.Nil(t, err)
+	assert.Empty(t, resources)
+
+	_, err = rh.GetResourceFromAllLevels(ResourceScope{Stage: "dev"}, "slo.yaml")
+	assert.NotNil(t, err)
+}
+
+func TestResourceHandler_ResolveResourceReturnsServerErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"code":500,"message":"internal error"}`))
+	}))
+	defer ts.Close()
+	rh := NewResourceHandler(ts.URL)
+
+	_, err := rh.ResolveResource(ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"}, "slo.yaml")
+	assert.NotNil(t, err)
+	assert.NotEqual(t, ResourceNotFoundError, err)
+}
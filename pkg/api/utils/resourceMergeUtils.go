@@ -0,0 +1,258 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ListMergeStrategy determines how lists are merged if they are defined on multiple levels
+type ListMergeStrategy string
+
+const (
+	// ListMergeReplace replaces a list with the list of the more specific level
+	ListMergeReplace ListMergeStrategy = "replace"
+	// ListMergeAppend appends the items of the more specific level to the list
+	ListMergeAppend ListMergeStrategy = "append"
+	// ListMergeByKey merges the items of the lists which have the same value for the merge key and appends all other items
+	ListMergeByKey ListMergeStrategy = "mergeByKey"
+)
+
+const defaultListMergeKey = "name"
+
+// YAMLMergeOptions configures how YAML resources are merged
+type YAMLMergeOptions struct {
+	// ListStrategy determines how lists are merged. Defaults to ListMergeReplace
+	ListStrategy ListMergeStrategy
+	// MergeKey is the key used to identify list items if ListMergeByKey is used. Defaults to "name"
+	MergeKey string
+}
+
+// MergedYAMLResource is the result of merging a YAML resource across the levels of a scope
+type MergedYAMLResource struct {
+	// Content is the merged content of the resource
+	Content interface{}
+	// Provenance maps the path of each value in the merged content to the level the value has been taken from.
+	// The path consists of the keys separated by dots and the indexes of list items, e.g. "image.tag" or "env[1].value"
+	Provenance map[string]ResourceScope
+	// Sources are the resources that have been merged, ordered from the least specific to the most specific level
+	Sources []*ScopedResource
+}
+
+// YAML returns the merged content as YAML document
+func (m *MergedYAMLResource) YAML() ([]byte, error) {
+	return yaml.Marshal(m.Content)
+}
+
+// ProvenanceReport returns a human readable report containing the level each value of the merged content has been taken from
+func (m *MergedYAMLResource) ProvenanceReport() string {
+	paths := make([]string, 0, len(m.Provenance))
+	for path := range m.Provenance {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	report := &strings.Builder{}
+	for _, path := range paths {
+		fmt.Fprintf(report, "%s: %s\n", path, m.Provenance[path])
+	}
+	return report.String()
+}
+
+// YAMLResourceMerger merges YAML resources which are defined on the project, stage and service level.
+// Values of more specific levels override values of less specific levels, maps are merged recursively
+type YAMLResourceMerger struct {
+	reader  ResourceReader
+	options YAMLMergeOptions
+}
+
+// NewYAMLResourceMerger creates a new YAMLResourceMerger reading the resources from the given reader, which can e.g. be
+// a ResourceHandler or a LocalResourceReader
+func NewYAMLResourceMerger(reader ResourceReader, options YAMLMergeOptions) *YAMLResourceMerger {
+	if options.ListStrategy == "" {
+		options.ListStrategy = ListMergeReplace
+	}
+	if options.MergeKey == "" {
+		options.MergeKey = defaultListMergeKey
+	}
+	return &YAMLResourceMerger{
+		reader:  reader,
+		options: options,
+	}
+}
+
+// Merge retrieves the resource with the given URI from all levels of the scope and merges their content.
+// If the resource is not available on any level, ResourceNotFoundError is returned
+func (m *YAMLResourceMerger) Merge(scope ResourceScope, resourceURI string) (*MergedYAMLResource, error) {
+	switch m.options.ListStrategy {
+	case ListMergeReplace, ListMergeAppend, ListMergeByKey:
+	default:
+		return nil, fmt.Errorf("unknown list merge strategy: %s", m.options.ListStrategy)
+	}
+
+	resources, err := getResourceFromAllLevels(m.reader, scope, resourceURI)
+	if err != nil {
+		return nil, err
+	}
+	if len(resources) == 0 {
+		return nil, ResourceNotFoundError
+	}
+
+	result := &MergedYAMLResource{Sources: resources}
+	provenance := &provenanceRecorder{scopes: map[string]ResourceScope{}}
+	for _, resource := range resources {
+		var content interface{}
+		if err := yaml.Unmarshal([]byte(resource.Resource.ResourceContent), &content); err != nil {
+			return nil, fmt.Errorf("could not parse resource %s of %s: %w", resourceURI, resource.Scope, err)
+		}
+		if content == nil {
+			continue
+		}
+		result.Content = m.mergeValues(result.Content, content, "", resource.Scope, provenance)
+	}
+	result.Provenance = provenance.scopes
+	return result, nil
+}
+
+// mergeValues merges src into dst and records the provenance of all values taken from src
+func (m *YAMLResourceMerger) mergeValues(dst, src interface{}, path string, scope ResourceScope, provenance *provenanceRecorder) interface{} {
+	dstMap, dstIsMap := dst.(map[string]interface{})
+	srcMap, srcIsMap := src.(map[string]interface{})
+	if dstIsMap && srcIsMap {
+		if len(dstMap) == 0 {
+			provenance.remove(dstMap, path)
+		}
+		for _, key := range sortedKeys(srcMap) {
+			keyPath := joinMapPath(path, key)
+			if existing, ok := dstMap[key]; ok {
+				dstMap[key] = m.mergeValues(existing, srcMap[key], keyPath, scope, provenance)
+			} else {
+				dstMap[key] = srcMap[key]
+				provenance.record(srcMap[key], keyPath, scope)
+			}
+		}
+		return dstMap
+	}
+
+	dstList, dstIsList := dst.([]interface{})
+	srcList, srcIsList := src.([]interface{})
+	if dstIsList && srcIsList && m.options.ListStrategy != ListMergeReplace {
+		return m.mergeLists(dstList, srcList, path, scope, provenance)
+	}
+
+	// the value of the more specific level replaces the existing value
+	provenance.remove(dst, path)
+	provenance.record(src, path, scope)
+	return src
+}
+
+func (m *YAMLResourceMerger) mergeLists(dst, src []interface{}, path string, scope ResourceScope, provenance *provenanceRecorder) []interface{} {
+	if len(dst) == 0 {
+		provenance.remove(dst, path)
+	}
+	for _, item := range src {
+		if m.options.ListStrategy == ListMergeByKey {
+			if idx := m.indexOfItem(dst, item); idx >= 0 {
+				dst[idx] = m.mergeValues(dst[idx], item, joinListPath(path, idx), scope, provenance)
+				continue
+			}
+		}
+		dst = append(dst, item)
+		provenance.record(item, joinListPath(path, len(dst)-1), scope)
+	}
+	return dst
+}
+
+// indexOfItem returns the index of the list item with the same value for the merge key, or -1 if there is none
+func (m *YAMLResourceMerger) indexOfItem(list []interface{}, item interface{}) int {
+	itemMap, ok := item.(map[string]interface{})
+	if !ok {
+		return -1
+	}
+	key, ok := itemMap[m.options.MergeKey]
+	if !ok {
+		return -1
+	}
+	for i, existing := range list {
+		existingMap, ok := existing.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if existingKey, ok := existingMap[m.options.MergeKey]; ok && fmt.Sprint(existingKey) == fmt.Sprint(key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// provenanceRecorder keeps track of the level each value of the merged content has been taken from
+type provenanceRecorder struct {
+	scopes map[string]ResourceScope
+}
+
+// record records the scope for the given value and all values nested within it
+func (p *provenanceRecorder) record(value interface{}, path string, scope ResourceScope) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			p.scopes[path] = scope
+		}
+		for key, child := range v {
+			p.record(child, joinMapPath(path, key), scope)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			p.scopes[path] = scope
+		}
+		for i, child := range v {
+			p.record(child, joinListPath(path, i), scope)
+		}
+	default:
+		p.scopes[path] = scope
+	}
+}
+
+// remove removes the provenance of the given value at the given path and all values nested within it
+func (p *provenanceRecorder) remove(value interface{}, path string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			delete(p.scopes, path)
+		}
+		for key, child := range v {
+			p.remove(child, joinMapPath(path, key))
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			delete(p.scopes, path)
+		}
+		for i, child := range v {
+			p.remove(child, joinListPath(path, i))
+		}
+	default:
+		delete(p.scopes, path)
+	}
+}
+
+func joinMapPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func joinListPath(path string, idx int) string {
+	return path + "[" + strconv.Itoa(idx) + "]"
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func newMergeTestReader(t *testing.T) *LocalResourceReader {
+	rootDir := t.TempDir()
+	writeTestFiles(t, rootDir, map[string]string{
+		"sockshop/resource/values.yaml": `
+image:
+  repository: docker.io/keptnexamples/carts
+  tag: 0.12.1
+replicas: 1
+env:
+  - name: LOG_LEVEL
+    value: info
+  - name: DB_HOST
+    value: carts-db
+`,
+		"sockshop/stage/dev/resource/values.yaml": `
+image:
+  tag: 0.12.3
+env:
+  - name: LOG_LEVEL
+    value: debug
+`,
+		"sockshop/stage/dev/service/carts/resource/values.yaml": `
+replicas: 2
+env:
+  - name: FEATURE_FLAG
+    value: "true"
+`,
+		"sockshop/stage/dev/resource/invalid.yaml": "key: [",
+	})
+	return NewLocalResourceReader(rootDir)
+}
+
+func TestYAMLResourceMerger_Merge(t *testing.T) {
+	projectLevel := ResourceScope{Project: "sockshop"}
+	stageLevel := ResourceScope{Project: "sockshop", Stage: "dev"}
+	serviceLevel := ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"}
+
+	tests := []struct {
+		name           string
+		options        YAMLMergeOptions
+		wantContent    string
+		wantProvenance map[string]ResourceScope
+	}{
+		{
+			name: "replace lists",
+			wantContent: `
+image:
+  repository: docker.io/keptnexamples/carts
+  tag: 0.12.3
+replicas: 2
+env:
+  - name: FEATURE_FLAG
+    value: "true"
+`,
+			wantProvenance: map[string]ResourceScope{
+				"image.repository": projectLevel,
+				"image.tag":        stageLevel,
+				"replicas":         serviceLevel,
+				"env[0].name":      serviceLevel,
+				"env[0].value":     serviceLevel,
+			},
+		},
+		{
+			name:    "append lists",
+			options: YAMLMergeOptions{ListStrategy: ListMergeAppend},
+			wantContent: `
+image:
+  repository: docker.io/keptnexamples/carts
+  tag: 0.12.3
+replicas: 2
+env:
+  - name: LOG_LEVEL
+    value: info
+  - name: DB_HOST
+    value: carts-db
+  - name: LOG_LEVEL
+    value: debug
+  - name: FEATURE_FLAG
+    value: "true"
+`,
+			wantProvenance: map[string]ResourceScope{
+				"image.repository": projectLevel,
+				"image.tag":        stageLevel,
+				"replicas":         serviceLevel,
+				"env[0].name":      projectLevel,
+				"env[0].value":     projectLevel,
+				"env[1].name":      projectLevel,
+				"env[1].value":     projectLevel,
+				"env[2].name":      stageLevel,
+				"env[2].value":     stageLevel,
+				"env[3].name":      serviceLevel,
+				"env[3].value":     serviceLevel,
+			},
+		},
+		{
+			name:    "merge lists by key",
+			options: YAMLMergeOptions{ListStrategy: ListMergeByKey},
+			wantContent: `
+image:
+  repository: docker.io/keptnexamples/carts
+  tag: 0.12.3
+replicas: 2
+env:
+  - name: LOG_LEVEL
+    value: debug
+  - name: DB_HOST
+    value: carts-db
+  - name: FEATURE_FLAG
+    value: "true"
+`,
+			wantProvenance: map[string]ResourceScope{
+				"image.repository": projectLevel,
+				"image.tag":        stageLevel,
+				"replicas":         serviceLevel,
+				"env[0].name":      stageLevel,
+				"env[0].value":     stageLevel,
+				"env[1].name":      projectLevel,
+				"env[1].value":     projectLevel,
+				"env[2].name":      serviceLevel,
+				"env[2].value":     serviceLevel,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merger := NewYAMLResourceMerger(newMergeTestReader(t), tt.options)
+			merged, err := merger.Merge(serviceLevel, "values.yaml")
+			require.Nil(t, err)
+
+			var wantContent interface{}
+			require.Nil(t, yaml.Unmarshal([]byte(tt.wantContent), &wantContent))
+			assert.Equal(t, wantContent, merged.Content)
+			assert.Equal(t, tt.wantProvenance, merged.Provenance)
+			assert.Len(t, merged.Sources, 3)
+
+			out, err := merged.YAML()
+			require.Nil(t, err)
+			var roundTripped interface{}
+			require.Nil(t, yaml.Unmarshal(out, &roundTripped))
+			assert.Equal(t, wantContent, roundTripped)
+		})
+	}
+}
+
+func TestYAMLResourceMerger_MergeReplacesMapWithScalar(t *testing.T) {
+	store := NewMemoryResourceStore()
+	store.SetResource(ResourceScope{Project: "sockshop"}, "config.yaml", "resources:\n  limits:\n    cpu: 1\n    memory: 1Gi\n")
+	store.SetResource(ResourceScope{Project: "sockshop", Stage: "dev"}, "config.yaml", "resources: none\n")
+
+	merged, err := NewYAMLResourceMerger(store, YAMLMergeOptions{}).Merge(ResourceScope{Project: "sockshop", Stage: "dev"}, "config.yaml")
+	require.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"resources": "none"}, merged.Content)
+	assert.Equal(t, map[string]ResourceScope{"resources": {Project: "sockshop", Stage: "dev"}}, merged.Provenance)
+	assert.Equal(t, "resources: project sockshop, stage dev\n", merged.ProvenanceReport())
+}
+
+func TestYAMLResourceMerger_MergeErrors(t *testing.T) {
+	reader := newMergeTestReader(t)
+
+	_, err := NewYAMLResourceMerger(reader, YAMLMergeOptions{}).Merge(ResourceScope{Project: "sockshop", Stage: "dev"}, "missing.yaml")
+	assert.Equal(t, ResourceNotFoundError, err)
+
+	_, err = NewYAMLResourceMerger(reader, YAMLMergeOptions{}).Merge(ResourceScope{Project: "sockshop", Stage: "dev"}, "invalid.yaml")
+	assert.NotNil(t, err)
+
+	_, err = NewYAMLResourceMerger(reader, YAMLMergeOptions{ListStrategy: "unknown"}).Merge(ResourceScope{Project: "sockshop"}, "values.yaml")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,261 @@
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+)
+
+const defaultResourceSyncConcurrency = 5
+const defaultResourceUploadBatchSize = 20
+
+// DownloadResourcesOptions can be used to configure DownloadResources
+type DownloadResourcesOptions struct {
+	// Concurrency is the maximum number of resources downloaded in parallel. Defaults to 5
+	Concurrency int
+}
+
+// DownloadResources downloads all resources of the given scope into the target directory and returns the paths of the written files.
+// The resource URIs are interpreted relative to the target directory, i.e. a leading slash as used by the configuration-service
+// is ignored. If any resource URI contains a ".." element or resolves to an absolute path, no resource is downloaded.
+func (r *ResourceHandler) DownloadResources(scope ResourceScope, targetDir string, opts DownloadResourcesOptions) ([]string, error) {
+	resources, err := r.GetAllResources(scope)
+	if err != nil {
+		return nil, fmt.Errorf("could not list resources of %s: %s", scope, err.Error())
+	}
+
+	targetPaths := make([]string, len(resources))
+	for i, resource := range resources {
+		if resource.ResourceURI == nil {
+			return nil, fmt.Errorf("resource list of %s contains a resource without URI", scope)
+		}
+		targetPath, err := getLocalResourcePath(targetDir, *resource.ResourceURI)
+		if err != nil {
+			return nil, err
+		}
+		targetPaths[i] = targetPath
+	}
+
+	errs := runWithConcurrency(len(resources), opts.Concurrency, func(i int) error {
+		return r.downloadResource(scope, *resources[i].ResourceURI, targetPaths[i])
+	})
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return targetPaths, nil
+}
+
+func (r *ResourceHandler) downloadResource(scope ResourceScope, resourceURI string, targetPath string) error {
+	resource, err := r.GetResource(scope, resourceURI)
+	if err != nil {
+		return fmt.Errorf("could not download resource %s of %s: %s", resourceURI, scope, err.Error())
+	}
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(targetPath, []byte(resource.ResourceContent), 0644)
+}
+
+// UploadResourcesOptions can be used to configure UploadResources
+type UploadResourcesOptions struct {
+	// DeleteRemoved deletes remote resources of the scope which do not exist in the local directory
+	DeleteRemoved bool
+	// BatchSize is the maximum number of resources sent within a single request. Defaults to 20
+	BatchSize int
+	// Concurrency is the maximum number of remote resources fetched in parallel for detecting changes. Defaults to 5
+	Concurrency int
+}
+
+// UploadResourcesResult contains the resource URIs affected by UploadResources
+type UploadResourcesResult struct {
+	Created   []string
+	Updated   []string
+	Deleted   []string
+	Unchanged []string
+	// Version is the version returned by the configuration-service for the last created or updated batch of resources
+	Version string
+}
+
+// UploadResources uploads all files of the source directory as resources of the given scope. The resource URIs are the paths
+// of the files relative to the source directory. Only files which do not exist remotely or whose content differs from the
+// remote content are uploaded. ".git" directories are skipped.
+func (r *ResourceHandler) UploadResources(scope ResourceScope, sourceDir string, opts UploadResourcesOptions) (*UploadResourcesResult, error) {
+	localResources, err := readLocalResources(sourceDir)
+	if err != nil {
+		return nil, err
+	}
+	remoteResources, err := r.GetAllResources(scope)
+	if err != nil {
+		return nil, fmt.Errorf("could not list resources of %s: %s", scope, err.Error())
+	}
+
+	// the configuration-service may return resource URIs with a leading slash
+	remoteURIs := map[string]string{}
+	for _, resource := range remoteResources {
+		if resource.ResourceURI != nil {
+			remoteURIs[strings.TrimPrefix(*resource.ResourceURI, "/")] = *resource.ResourceURI
+		}
+	}
+
+	result := &UploadResourcesResult{}
+	toCreate := []*models.Resource{}
+	existing := []*models.Resource{}
+	for _, resource := range localResources {
+		if remoteURI, ok := remoteURIs[*resource.ResourceURI]; ok {
+			existing = append(existing, &models.Resource{ResourceURI: &remoteURI, ResourceContent: resource.ResourceContent})
+			continue
+		}
+		toCreate = append(toCreate, resource)
+		result.Created = append(result.Created, *resource.ResourceURI)
+	}
+
+	changed := make([]bool, len(existing))
+	errs := runWithConcurrency(len(existing), opts.Concurrency, func(i int) error {
+		remote, err := r.GetResource(scope, *existing[i].ResourceURI)
+		if err != nil {
+			return fmt.Errorf("could not retrieve resource %s of %s: %s", *existing[i].ResourceURI, scope, err.Error())
+		}
+		changed[i] = remote.ResourceContent != existing[i].ResourceContent
+		return nil
+	})
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	toUpdate := []*models.Resource{}
+	for i, resource := range existing {
+		if changed[i] {
+			toUpdate = append(toUpdate, resource)
+			result.Updated = append(result.Updated, *resource.ResourceURI)
+		} else {
+			result.Unchanged = append(result.Unchanged, *resource.ResourceURI)
+		}
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultResourceUploadBatchSize
+	}
+	resourcesURL := r.getScopeURL(scope) + "/resource"
+	for _, batch := range splitResources(toCreate, batchSize) {
+		if result.Version, err = r.createResources(resourcesURL, batch); err != nil {
+			return nil, fmt.Errorf("could not create resources of %s: %s", scope, err.Error())
+		}
+	}
+	for _, batch := range splitResources(toUpdate, batchSize) {
+		if result.Version, err = r.updateResources(resourcesURL, batch); err != nil {
+			return nil, fmt.Errorf("could not update resources of %s: %s", scope, err.Error())
+		}
+	}
+
+	if opts.DeleteRemoved {
+		localURIs := map[string]bool{}
+		for _, resource := range localResources {
+			localURIs[*resource.ResourceURI] = true
+		}
+		for uri, remoteURI := range remoteURIs {
+			if localURIs[uri] {
+				continue
+			}
+			err := r.deleteResourceAndCheckStatus(resourcesURL + "/" + url.QueryEscape(remoteURI))
+			if err == ResourceNotFoundError {
+				// the resource has already been deleted in the meantime
+				continue
+			} else if err != nil {
+				return nil, fmt.Errorf("could not delete resource %s of %s: %s", remoteURI, scope, err.Error())
+			}
+			result.Deleted = append(result.Deleted, remoteURI)
+		}
+		sort.Strings(result.Deleted)
+	}
+
+	return result, nil
+}
+
+// readLocalResources reads all files within the directory and returns them as resources, ordered by their resource URI
+func readLocalResources(dir string) ([]*models.Resource, error) {
+	resources := []*models.Resource{}
+	err := filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relativePath, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		content, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		resourceURI := filepath.ToSlash(relativePath)
+		resources = append(resources, &models.Resource{ResourceURI: &resourceURI, ResourceContent: string(content)})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not read resources from %s: %s", dir, err.Error())
+	}
+	return resources, nil
+}
+
+func splitResources(resources []*models.Resource, batchSize int) [][]*models.Resource {
+	batches := [][]*models.Resource{}
+	for start := 0; start < len(resources); start += batchSize {
+		end := start + batchSize
+		if end > len(resources) {
+			end = len(resources)
+		}
+		batches = append(batches, resources[start:end])
+	}
+	return batches
+}
+
+// getLocalResourcePath returns the path of the given resource URI within the directory and ensures that it
+// does not point outside of the directory
+func getLocalResourcePath(dir string, resourceURI string) (string, error) {
+	normalizedURI, err := NormalizeResourceURI(resourceURI)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, filepath.FromSlash(normalizedURI)), nil
+}
+
+// runWithConcurrency calls fn for each index in [0, n) with at most the given number of parallel calls
+// and returns the errors in the order of the indices
+func runWithConcurrency(n int, concurrency int, fn func(i int) error) []error {
+	if concurrency <= 0 {
+		concurrency = defaultResourceSyncConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+	errs := make([]error, n)
+	wg := sync.WaitGroup{}
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = fn(i)
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
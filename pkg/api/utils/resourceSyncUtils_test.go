@@ -0,0 +1,320 @@
+package api
+
+import (
+	b64 "encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/go-utils/pkg/common/strutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConfigurationService is a test server which stores resources keyed by their scope path
+// (e.g. "/v1/project/sockshop/stage/dev") and their resource URI
+type fakeConfigurationService struct {
+	*httptest.Server
+	t         *testing.T
+	lock      sync.Mutex
+	resources map[string]map[string]string
+	writes    int
+}
+
+func newFakeConfigurationService(t *testing.T, resources map[string]map[string]string) *fakeConfigurationService {
+	f := &fakeConfigurationService{t: t, resources: resources}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.handle))
+	return f
+}
+
+func (f *fakeConfigurationService) handle(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	path := r.URL.EscapedPath()
+	idx := strings.LastIndex(path, "/resource")
+	if idx < 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	scopePath := path[:idx]
+	resourcePath := strings.TrimPrefix(strings.TrimPrefix(path[idx:], "/resource"), "/")
+
+	if r.Method == http.MethodPost || r.Method == http.MethodPut {
+		request := resourceRequest{}
+		if resourcePath != "" {
+			// single resources are written without the surrounding request
+			resource := &models.Resource{}
+			require.Nil(f.t, json.NewDecoder(r.Body).Decode(resource))
+			request.Resources = []*models.Resource{resource}
+		} else {
+			require.Nil(f.t, json.NewDecoder(r.Body).Decode(&request))
+		}
+		if f.resources[scopePath] == nil {
+			f.resources[scopePath] = map[string]string{}
+		}
+		for _, resource := range request.Resources {
+			content, err := b64.StdEncoding.DecodeString(resource.ResourceContent)
+			require.Nil(f.t, err)
+			f.resources[scopePath][*resource.ResourceURI] = string(content)
+		}
+		f.writes++
+		body, _ := json.Marshal(models.Version{Version: fmt.Sprintf("commit-%d", f.writes)})
+		w.Write(body)
+		return
+	}
+
+	scopeResources, ok := f.resources[scopePath]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":404,"message":"scope not found"}`))
+		return
+	}
+
+	if resourcePath == "" {
+		list := models.Resources{Resources: []*models.Resource{}}
+		for uri := range scopeResources {
+			list.Resources = append(list.Resources, &models.Resource{ResourceURI: strutils.Stringp(uri)})
+		}
+		body, _ := json.Marshal(list)
+		w.Write(body)
+		return
+	}
+
+	uri, err := url.QueryUnescape(resourcePath)
+	require.Nil(f.t, err)
+	content, ok := scopeResources[uri]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if r.Method == http.MethodDelete {
+		delete(scopeResources, uri)
+		return
+	}
+	body, _ := json.Marshal(models.Resource{
+		ResourceURI:     strutils.Stringp(uri),
+		ResourceContent: b64.StdEncoding.EncodeToString([]byte(content)),
+	})
+	w.Write(body)
+}
+
+func TestResourceHandler_DownloadResources(t *testing.T) {
+	ts := newFakeConfigurationService(t, map[string]map[string]string{
+		"/v1/project/sockshop/stage/dev/service/carts": {
+			"/helm/carts/Chart.yaml":            "name: carts",
+			"/helm/carts/templates/deploy.yaml": "kind: Deployment",
+			"slo.yaml":                          "objectives: []",
+		},
+		"/v1/project/sockshop": {
+			"shipyard.yaml": "kind: Shipyard",
+		},
+	})
+	defer ts.Close()
+
+	rh := NewResourceHandler(ts.URL)
+
+	targetDir := t.TempDir()
+	files, err := rh.DownloadResources(ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"}, targetDir, DownloadResourcesOptions{Concurrency: 2})
+	require.Nil(t, err)
+	assert.Len(t, files, 3)
+
+	content, err := ioutil.ReadFile(filepath.Join(targetDir, "helm", "carts", "templates", "deploy.yaml"))
+	require.Nil(t, err)
+	assert.Equal(t, "kind: Deployment", string(content))
+	content, err = ioutil.ReadFile(filepath.Join(targetDir, "slo.yaml"))
+	require.Nil(t, err)
+	assert.Equal(t, "objectives: []", string(content))
+
+	projectDir := t.TempDir()
+	files, err = rh.DownloadResources(ResourceScope{Project: "sockshop"}, projectDir, DownloadResourcesOptions{})
+	require.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join(projectDir, "shipyard.yaml")}, files)
+}
+
+func TestResourceHandler_DownloadResourcesRefusesUnsafePaths(t *testing.T) {
+	var lock sync.Mutex
+	downloads := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/resource") {
+			w.Write([]byte(`{"resources":[{"resourceURI":"ok.yaml"},{"resourceURI":"../../etc/passwd"}]}`))
+			return
+		}
+		lock.Lock()
+		downloads++
+		lock.Unlock()
+		w.Write([]byte(`{"resourceURI":"ok.yaml","resourceContent":""}`))
+	}))
+	defer ts.Close()
+
+	rh := NewResourceHandler(ts.URL)
+	_, err := rh.DownloadResources(ResourceScope{Project: "sockshop", Stage: "dev"}, t.TempDir(), DownloadResourcesOptions{})
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, downloads)
+}
+
+func TestResourceHandler_DownloadResourcesInvalidScope(t *testing.T) {
+	rh := NewResourceHandler("localhost")
+	_, err := rh.DownloadResources(ResourceScope{Project: "sockshop", Service: "carts"}, t.TempDir(), DownloadResourcesOptions{})
+	assert.NotNil(t, err)
+}
+
+func Test_getLocalResourcePath(t *testing.T) {
+	tests := []struct {
+		name        string
+		resourceURI string
+		want        string
+		wantRule    string
+	}{
+		{name: "simple file", resourceURI: "slo.yaml", want: filepath.Join("base", "slo.yaml")},
+		{name: "leading slash", resourceURI: "/helm/carts.tgz", want: filepath.Join("base", "helm", "carts.tgz")},
+		{name: "redundant elements", resourceURI: "helm/./carts//values.yaml", want: filepath.Join("base", "helm", "carts", "values.yaml")},
+		{name: "empty", resourceURI: "", wantRule: ResourceURIRuleNotEmpty},
+		{name: "parent directory", resourceURI: "../shipyard.yaml", wantRule: ResourceURIRuleNoParentReference},
+		{name: "nested parent directory", resourceURI: "helm/../../shipyard.yaml", wantRule: ResourceURIRuleNoParentReference},
+		{name: "absolute path", resourceURI: "//etc/passwd", wantRule: ResourceURIRuleNotAbsolute},
+		{name: "volume name", resourceURI: "C:/Windows/win.ini", wantRule: ResourceURIRuleNotAbsolute},
+		{name: "backslash", resourceURI: "..\\shipyard.yaml", wantRule: ResourceURIRuleNoBackslash},
+		{name: "nul byte", resourceURI: "slo.yaml\x00", wantRule: ResourceURIRuleNoControlChars},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getLocalResourcePath("base", tt.resourceURI)
+			if tt.wantRule != "" {
+				uriErr := &InvalidResourceURIError{}
+				require.True(t, errors.As(err, &uriErr))
+				assert.Equal(t, tt.wantRule, uriErr.Rule)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		require.Nil(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		require.Nil(t, ioutil.WriteFile(filePath, []byte(content), 0644))
+	}
+}
+
+func TestResourceHandler_UploadResources(t *testing.T) {
+	ts := newFakeConfigurationService(t, map[string]map[string]string{
+		"/v1/project/sockshop/stage/dev/service/carts": {
+			"/helm/carts/Chart.yaml": "name: carts",
+			"/slo.yaml":              "objectives: []",
+			"/obsolete.yaml":         "obsolete",
+		},
+	})
+	defer ts.Close()
+
+	sourceDir := t.TempDir()
+	writeTestFiles(t, sourceDir, map[string]string{
+		"helm/carts/Chart.yaml":            "name: carts",
+		"helm/carts/templates/deploy.yaml": "kind: Deployment",
+		"slo.yaml":                         "objectives: [response_time]",
+		"sli.yaml":                         "indicators: {}",
+		".git/HEAD":                        "ref: refs/heads/dev",
+	})
+
+	rh := NewResourceHandler(ts.URL)
+	result, err := rh.UploadResources(ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"}, sourceDir, UploadResourcesOptions{BatchSize: 1})
+	require.Nil(t, err)
+
+	assert.Equal(t, []string{"helm/carts/templates/deploy.yaml", "sli.yaml"}, result.Created)
+	assert.Equal(t, []string{"/slo.yaml"}, result.Updated)
+	assert.Equal(t, []string{"/helm/carts/Chart.yaml"}, result.Unchanged)
+	assert.Empty(t, result.Deleted)
+	// two batches for the created resources and one for the updated resource
+	assert.Equal(t, "commit-3", result.Version)
+
+	scopeResources := ts.resources["/v1/project/sockshop/stage/dev/service/carts"]
+	assert.Equal(t, "kind: Deployment", scopeResources["helm/carts/templates/deploy.yaml"])
+	assert.Equal(t, "objectives: [response_time]", scopeResources["/slo.yaml"])
+	assert.Equal(t, "obsolete", scopeResources["/obsolete.yaml"])
+	assert.NotContains(t, scopeResources, ".git/HEAD")
+}
+
+func TestResourceHandler_UploadResourcesDeleteRemoved(t *testing.T) {
+	ts := newFakeConfigurationService(t, map[string]map[string]string{
+		"/v1/project/sockshop/stage/dev": {
+			"slo.yaml":       "objectives: []",
+			"obsolete.yaml":  "obsolete",
+			"obsolete2.yaml": "obsolete",
+		},
+	})
+	defer ts.Close()
+
+	sourceDir := t.TempDir()
+	writeTestFiles(t, sourceDir, map[string]string{
+		"slo.yaml": "objectives: []",
+	})
+
+	rh := NewResourceHandler(ts.URL)
+	result, err := rh.UploadResources(ResourceScope{Project: "sockshop", Stage: "dev"}, sourceDir, UploadResourcesOptions{DeleteRemoved: true})
+	require.Nil(t, err)
+
+	assert.Empty(t, result.Created)
+	assert.Empty(t, result.Updated)
+	assert.Equal(t, []string{"obsolete.yaml", "obsolete2.yaml"}, result.Deleted)
+	assert.Equal(t, "", result.Version)
+	assert.Equal(t, map[string]string{"slo.yaml": "objectives: []"}, ts.resources["/v1/project/sockshop/stage/dev"])
+}
+
+func TestResourceHandler_UploadResourcesDeleteRemovedFails(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"code":500,"message":"could not commit changes"}`))
+			return
+		}
+		body, _ := json.Marshal(models.Resources{Resources: []*models.Resource{{ResourceURI: strutils.Stringp("obsolete.yaml")}}})
+		w.Write(body)
+	}))
+	defer ts.Close()
+
+	rh := NewResourceHandler(ts.URL)
+	result, err := rh.UploadResources(ResourceScope{Project: "sockshop", Stage: "dev"}, t.TempDir(), UploadResourcesOptions{DeleteRemoved: true})
+	require.NotNil(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "could not commit changes")
+}
+
+func TestResourceHandler_UploadResourcesDeleteRemovedAlreadyDeleted(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"Resource not found"}`))
+			return
+		}
+		body, _ := json.Marshal(models.Resources{Resources: []*models.Resource{{ResourceURI: strutils.Stringp("obsolete.yaml")}}})
+		w.Write(body)
+	}))
+	defer ts.Close()
+
+	rh := NewResourceHandler(ts.URL)
+	result, err := rh.UploadResources(ResourceScope{Project: "sockshop", Stage: "dev"}, t.TempDir(), UploadResourcesOptions{DeleteRemoved: true})
+	require.Nil(t, err)
+	assert.Empty(t, result.Deleted)
+}
+
+func TestResourceHandler_UploadResourcesMissingDirectory(t *testing.T) {
+	rh := NewResourceHandler("localhost")
+	_, err := rh.UploadResources(ResourceScope{Project: "sockshop"}, filepath.Join(t.TempDir(), "missing"), UploadResourcesOptions{})
+	assert.NotNil(t, err)
+}
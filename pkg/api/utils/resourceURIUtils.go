@@ -0,0 +1,117 @@
+package api
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Rules checked by the ResourceURIValidator, which are contained in the InvalidResourceURIError
+const (
+	ResourceURIRuleNotEmpty          = "must not be empty"
+	ResourceURIRuleMaxLength         = "must not exceed the maximum length"
+	ResourceURIRuleValidUTF8         = "must be valid UTF-8"
+	ResourceURIRuleNoControlChars    = "must not contain control characters"
+	ResourceURIRuleNoBackslash       = "must not contain backslashes"
+	ResourceURIRuleNoLeadingSlash    = "must not start with a slash"
+	ResourceURIRuleNotAbsolute       = "must not be an absolute path"
+	ResourceURIRuleNoParentReference = "must not contain parent directory references (..)"
+	ResourceURIRuleRefersToFile      = "must refer to a file"
+)
+
+// DefaultMaxResourceURILength is the default maximum length of resource URIs
+const DefaultMaxResourceURILength = 1024
+
+var volumeNameRegex = regexp.MustCompile(`^[a-zA-Z]:`)
+
+// InvalidResourceURIError is returned if a resource URI violates a rule of the ResourceURIValidator
+type InvalidResourceURIError struct {
+	ResourceURI string
+	// Rule is the violated rule, e.g. ResourceURIRuleNoParentReference
+	Rule string
+}
+
+func (e *InvalidResourceURIError) Error() string {
+	return fmt.Sprintf("invalid resource URI %q: %s", e.ResourceURI, e.Rule)
+}
+
+// ResourceURIValidator validates and normalizes resource URIs
+type ResourceURIValidator struct {
+	// AllowLeadingSlash allows resource URIs to start with a single slash, e.g. "/helm/values.yaml"
+	AllowLeadingSlash bool
+	// MaxLength is the maximum length of resource URIs. Defaults to DefaultMaxResourceURILength
+	MaxLength int
+}
+
+// DefaultResourceURIValidator is used for resource URIs passed to the ResourceHandler, which accepts resource URIs
+// with and without a leading slash
+var DefaultResourceURIValidator = ResourceURIValidator{AllowLeadingSlash: true}
+
+// ValidateResourceURI validates the resource URI using the DefaultResourceURIValidator
+func ValidateResourceURI(resourceURI string) error {
+	return DefaultResourceURIValidator.Validate(resourceURI)
+}
+
+// NormalizeResourceURI normalizes the resource URI using the DefaultResourceURIValidator
+func NormalizeResourceURI(resourceURI string) (string, error) {
+	return DefaultResourceURIValidator.Normalize(resourceURI)
+}
+
+// Validate checks whether the resource URI is safe to be used as path of a file below a directory.
+// If it is not, an InvalidResourceURIError containing the violated rule is returned
+func (v ResourceURIValidator) Validate(resourceURI string) error {
+	_, err := v.Normalize(resourceURI)
+	return err
+}
+
+// Normalize validates the resource URI and returns it as a clean, slash-separated path without leading slash,
+// e.g. "helm/carts/values.yaml" for "/helm/./carts//values.yaml"
+func (v ResourceURIValidator) Normalize(resourceURI string) (string, error) {
+	invalid := func(rule string) (string, error) {
+		return "", &InvalidResourceURIError{ResourceURI: resourceURI, Rule: rule}
+	}
+
+	maxLength := v.MaxLength
+	if maxLength <= 0 {
+		maxLength = DefaultMaxResourceURILength
+	}
+	switch {
+	case resourceURI == "":
+		return invalid(ResourceURIRuleNotEmpty)
+	case len(resourceURI) > maxLength:
+		return invalid(ResourceURIRuleMaxLength)
+	case !utf8.ValidString(resourceURI):
+		return invalid(ResourceURIRuleValidUTF8)
+	case strings.IndexFunc(resourceURI, unicode.IsControl) >= 0:
+		return invalid(ResourceURIRuleNoControlChars)
+	case strings.Contains(resourceURI, "\\"):
+		return invalid(ResourceURIRuleNoBackslash)
+	}
+
+	relativeURI := resourceURI
+	if strings.HasPrefix(relativeURI, "/") {
+		if !v.AllowLeadingSlash {
+			return invalid(ResourceURIRuleNoLeadingSlash)
+		}
+		relativeURI = strings.TrimPrefix(relativeURI, "/")
+	}
+	if strings.HasPrefix(relativeURI, "/") || volumeNameRegex.MatchString(relativeURI) {
+		return invalid(ResourceURIRuleNotAbsolute)
+	}
+	for _, element := range strings.Split(relativeURI, "/") {
+		if element == ".." {
+			return invalid(ResourceURIRuleNoParentReference)
+		}
+	}
+	if relativeURI == "" || strings.HasSuffix(relativeURI, "/") {
+		return invalid(ResourceURIRuleRefersToFile)
+	}
+	normalized := path.Clean(relativeURI)
+	if normalized == "." {
+		return invalid(ResourceURIRuleRefersToFile)
+	}
+	return normalized, nil
+}
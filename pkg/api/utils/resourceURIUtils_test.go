@@ -0,0 +1,91 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/go-utils/pkg/common/strutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceURIValidator_Normalize(t *testing.T) {
+	tests := []struct {
+		name        string
+		validator   ResourceURIValidator
+		resourceURI string
+		want        string
+		wantRule    string
+	}{
+		{name: "simple file", validator: DefaultResourceURIValidator, resourceURI: "slo.yaml", want: "slo.yaml"},
+		{name: "leading slash", validator: DefaultResourceURIValidator, resourceURI: "/helm/carts/values.yaml", want: "helm/carts/values.yaml"},
+		{name: "redundant elements", validator: DefaultResourceURIValidator, resourceURI: "helm/./carts//values.yaml", want: "helm/carts/values.yaml"},
+		{name: "special characters", validator: DefaultResourceURIValidator, resourceURI: "dynatrace/sli (v2) & more.yaml", want: "dynatrace/sli (v2) & more.yaml"},
+		{name: "empty", validator: DefaultResourceURIValidator, resourceURI: "", wantRule: ResourceURIRuleNotEmpty},
+		{name: "too long", validator: ResourceURIValidator{MaxLength: 10}, resourceURI: "helm/values.yaml", wantRule: ResourceURIRuleMaxLength},
+		{name: "invalid utf-8", validator: DefaultResourceURIValidator, resourceURI: "slo\xff.yaml", wantRule: ResourceURIRuleValidUTF8},
+		{name: "newline", validator: DefaultResourceURIValidator, resourceURI: "slo.yaml\n", wantRule: ResourceURIRuleNoControlChars},
+		{name: "nul byte", validator: DefaultResourceURIValidator, resourceURI: "slo\x00.yaml", wantRule: ResourceURIRuleNoControlChars},
+		{name: "backslash", validator: DefaultResourceURIValidator, resourceURI: "helm\\values.yaml", wantRule: ResourceURIRuleNoBackslash},
+		{name: "leading slash not allowed", validator: ResourceURIValidator{}, resourceURI: "/slo.yaml", wantRule: ResourceURIRuleNoLeadingSlash},
+		{name: "absolute path", validator: DefaultResourceURIValidator, resourceURI: "//etc/passwd", wantRule: ResourceURIRuleNotAbsolute},
+		{name: "volume name", validator: DefaultResourceURIValidator, resourceURI: "C:/Windows/win.ini", wantRule: ResourceURIRuleNotAbsolute},
+		{name: "parent directory", validator: DefaultResourceURIValidator, resourceURI: "../shipyard.yaml", wantRule: ResourceURIRuleNoParentReference},
+		{name: "nested parent directory", validator: DefaultResourceURIValidator, resourceURI: "helm/../slo.yaml", wantRule: ResourceURIRuleNoParentReference},
+		{name: "only slash", validator: DefaultResourceURIValidator, resourceURI: "/", wantRule: ResourceURIRuleRefersToFile},
+		{name: "directory", validator: DefaultResourceURIValidator, resourceURI: "helm/", wantRule: ResourceURIRuleRefersToFile},
+		{name: "current directory", validator: DefaultResourceURIValidator, resourceURI: ".", wantRule: ResourceURIRuleRefersToFile},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.validator.Normalize(tt.resourceURI)
+			if tt.wantRule != "" {
+				uriErr := &InvalidResourceURIError{}
+				require.True(t, errors.As(err, &uriErr))
+				assert.Equal(t, tt.wantRule, uriErr.Rule)
+				assert.Equal(t, tt.resourceURI, uriErr.ResourceURI)
+				assert.Contains(t, err.Error(), tt.wantRule)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Nil(t, tt.validator.Validate(tt.resourceURI))
+		})
+	}
+}
+
+func TestResourceHandler_RejectsInvalidResourceURIs(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+	rh := NewResourceHandler(ts.URL)
+	scope := ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"}
+	invalidURI := "../../shipyard.yaml"
+
+	_, err := rh.GetResource(scope, invalidURI)
+	assert.NotNil(t, err)
+	_, err = rh.GetServiceResource("sockshop", "dev", "carts", invalidURI)
+	assert.NotNil(t, err)
+	err = rh.DeleteStageResource("sockshop", "dev", invalidURI)
+	assert.NotNil(t, err)
+	_, err = rh.UpdateProjectResource("sockshop", &models.Resource{ResourceURI: strutils.Stringp(invalidURI)})
+	assert.NotNil(t, err)
+	_, err = rh.UpdateServiceResources("sockshop", "dev", "carts", []*models.Resource{{ResourceURI: strutils.Stringp("slo.yaml\x00")}})
+	assert.NotNil(t, err)
+	_, errObj := rh.CreateResources("sockshop", "dev", "carts", []*models.Resource{{ResourceURI: strutils.Stringp(invalidURI)}})
+	assert.NotNil(t, errObj)
+	_, err = rh.UpdateResourceContent(scope, invalidURI, bytes.NewReader(nil))
+	assert.NotNil(t, err)
+	_, err = rh.GetResourceAtVersion(scope, strings.Repeat("a", DefaultMaxResourceURILength+1), "c1")
+	assert.NotNil(t, err)
+
+	assert.Equal(t, 0, requests)
+}
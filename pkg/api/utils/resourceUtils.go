@@ -6,10 +6,12 @@ import (
 	b64 "encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 
 	"github.com/keptn/go-utils/pkg/api/models"
 )
@@ -21,6 +23,8 @@ type ResourceHandler struct {
 	AuthHeader string
 	HTTPClient *http.Client
 	Scheme     string
+	// MaxContentSize limits the size of resource content read or written as bytes. Defaults to DefaultMaxResourceContentSize
+	MaxContentSize int64
 }
 
 type resourceRequest struct {
@@ -31,6 +35,64 @@ const configurationServiceBaseUrl = "configuration-service"
 
 var ResourceNotFoundError = errors.New("Resource not found")
 
+// ErrResourceNotModified is returned by conditional requests if the resource has not been modified
+var ErrResourceNotModified = errors.New("resource not modified")
+
+var defaultTransportTLSConfigOnce sync.Once
+
+// disableDefaultTransportTLSVerification disables TLS verification for the http.DefaultTransport.
+// This is only done once, since resources may be retrieved concurrently
+func disableDefaultTransportTLSVerification() {
+	defaultTransportTLSConfigOnce.Do(func() {
+		http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	})
+}
+
+// ResourceScope determines the level a resource is stored on: If only the project is set, it refers to the project level,
+// if project and stage are set, it refers to the stage level, and if project, stage and service are set, it refers to the service level
+type ResourceScope struct {
+	Project string
+	Stage   string
+	Service string
+}
+
+// Validate checks whether the scope refers to a valid level
+func (s ResourceScope) Validate() error {
+	if s.Project == "" {
+		return errors.New("resource scope must contain a project")
+	}
+	if s.Service != "" && s.Stage == "" {
+		return errors.New("resource scope must contain a stage if a service is set")
+	}
+	return nil
+}
+
+// IsProjectLevel returns true if the scope refers to the project level
+func (s ResourceScope) IsProjectLevel() bool {
+	return s.Stage == "" && s.Service == ""
+}
+
+// IsStageLevel returns true if the scope refers to the stage level
+func (s ResourceScope) IsStageLevel() bool {
+	return s.Stage != "" && s.Service == ""
+}
+
+// IsServiceLevel returns true if the scope refers to the service level
+func (s ResourceScope) IsServiceLevel() bool {
+	return s.Stage != "" && s.Service != ""
+}
+
+func (s ResourceScope) String() string {
+	str := "project " + s.Project
+	if s.Stage != "" {
+		str += ", stage " + s.Stage
+	}
+	if s.Service != "" {
+		str += ", service " + s.Service
+	}
+	return str
+}
+
 // NewResourceHandler returns a new ResourceHandler which sends all requests directly to the configuration-service
 func NewResourceHandler(baseURL string) *ResourceHandler {
 	if strings.Contains(baseURL, "https://") {
@@ -89,6 +151,9 @@ func (r *ResourceHandler) getHTTPClient() *http.Client {
 // CreateResources creates a resource for the specified entity
 func (r *ResourceHandler) CreateResources(project string, stage string, service string, resources []*models.Resource) (*models.EventContext, *models.Error) {
 
+	if err := validateResourceURIs(resources); err != nil {
+		return nil, buildErrorResponse(err.Error())
+	}
 	copiedResources := make([]*models.Resource, len(resources), len(resources))
 	for i, val := range resources {
 		resourceContent := b64.StdEncoding.EncodeToString([]byte(val.ResourceContent))
@@ -112,6 +177,57 @@ func (r *ResourceHandler) CreateResources(project string, stage string, service
 	}
 }
 
+// GetResource retrieves a resource of the given scope from the configuration service
+func (r *ResourceHandler) GetResource(scope ResourceScope, resourceURI string) (*models.Resource, error) {
+	resourceURL, err := r.getResourceURL(scope, resourceURI)
+	if err != nil {
+		return nil, err
+	}
+	return r.getResource(resourceURL)
+}
+
+// GetAllResources returns a list of all resources of the given scope
+func (r *ResourceHandler) GetAllResources(scope ResourceScope) ([]*models.Resource, error) {
+	if err := scope.Validate(); err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(r.getScopeURL(scope) + "/resource")
+	if err != nil {
+		return nil, err
+	}
+	return r.getAllResources(u)
+}
+
+// PutResource creates or updates a resource of the given scope and returns the resulting version
+func (r *ResourceHandler) PutResource(scope ResourceScope, resource *models.Resource) (string, error) {
+	if err := scope.Validate(); err != nil {
+		return "", err
+	}
+	return r.updateResources(r.getScopeURL(scope)+"/resource", []*models.Resource{resource})
+}
+
+// getResourceURL validates the scope and the resource URI and returns the URL of the resource
+func (r *ResourceHandler) getResourceURL(scope ResourceScope, resourceURI string) (string, error) {
+	if err := scope.Validate(); err != nil {
+		return "", err
+	}
+	if err := ValidateResourceURI(resourceURI); err != nil {
+		return "", err
+	}
+	return r.getScopeURL(scope) + "/resource/" + url.QueryEscape(resourceURI), nil
+}
+
+func (r *ResourceHandler) getScopeURL(scope ResourceScope) string {
+	scopeURL := r.Scheme + "://" + r.getBaseURL() + "/v1/project/" + scope.Project
+	if scope.Stage != "" {
+		scopeURL += "/stage/" + scope.Stage
+	}
+	if scope.Service != "" {
+		scopeURL += "/service/" + url.QueryEscape(scope.Service)
+	}
+	return scopeURL
+}
+
 // CreateProjectResources creates multiple project resources
 func (r *ResourceHandler) CreateProjectResources(project string, resources []*models.Resource) (string, error) {
 	return r.createResources(r.Scheme+"://"+r.BaseURL+"/v1/project/"+project+"/resource", resources)
@@ -119,6 +235,9 @@ func (r *ResourceHandler) CreateProjectResources(project string, resources []*mo
 
 // GetProjectResource retrieves a project resource from the configuration service
 func (r *ResourceHandler) GetProjectResource(project string, resourceURI string) (*models.Resource, error) {
+	if err := ValidateResourceURI(resourceURI); err != nil {
+		return nil, err
+	}
 	return r.getResource(r.Scheme + "://" + r.BaseURL + "/v1/project/" + project + "/resource/" + url.QueryEscape(resourceURI))
 }
 
@@ -129,6 +248,9 @@ func (r *ResourceHandler) UpdateProjectResource(project string, resource *models
 
 // DeleteProjectResource deletes a project resource
 func (r *ResourceHandler) DeleteProjectResource(project string, resourceURI string) error {
+	if err := ValidateResourceURI(resourceURI); err != nil {
+		return err
+	}
 	return r.deleteResource(r.Scheme + "://" + r.BaseURL + "/v1/project/" + project + "/resource/" + url.QueryEscape(resourceURI))
 }
 
@@ -144,6 +266,9 @@ func (r *ResourceHandler) CreateStageResources(project string, stage string, res
 
 // GetStageResource retrieves a stage resource from the configuration service
 func (r *ResourceHandler) GetStageResource(project string, stage string, resourceURI string) (*models.Resource, error) {
+	if err := ValidateResourceURI(resourceURI); err != nil {
+		return nil, err
+	}
 	return r.getResource(r.Scheme + "://" + r.BaseURL + "/v1/project/" + project + "/stage/" + stage + "/resource/" + url.QueryEscape(resourceURI))
 }
 
@@ -159,6 +284,9 @@ func (r *ResourceHandler) UpdateStageResources(project string, stage string, res
 
 // DeleteStageResource deletes a stage resource
 func (r *ResourceHandler) DeleteStageResource(project string, stage string, resourceURI string) error {
+	if err := ValidateResourceURI(resourceURI); err != nil {
+		return err
+	}
 	return r.deleteResource(r.Scheme + "://" + r.BaseURL + "/v1/project/" + project + "/stage/" + stage + "/resource/" + url.QueryEscape(resourceURI))
 }
 
@@ -169,6 +297,9 @@ func (r *ResourceHandler) CreateServiceResources(project string, stage string, s
 
 // GetServiceResource retrieves a service resource from the configuration service
 func (r *ResourceHandler) GetServiceResource(project string, stage string, service string, resourceURI string) (*models.Resource, error) {
+	if err := ValidateResourceURI(resourceURI); err != nil {
+		return nil, err
+	}
 	return r.getResource(r.Scheme + "://" + r.BaseURL + "/v1/project/" + project + "/stage/" + stage + "/service/" + url.QueryEscape(service) + "/resource/" + url.QueryEscape(resourceURI))
 }
 
@@ -184,6 +315,9 @@ func (r *ResourceHandler) UpdateServiceResources(project string, stage string, s
 
 // DeleteServiceResource deletes a service resource
 func (r *ResourceHandler) DeleteServiceResource(project string, stage string, service string, resourceURI string) error {
+	if err := ValidateResourceURI(resourceURI); err != nil {
+		return err
+	}
 	return r.deleteResource(r.Scheme + "://" + r.BaseURL + "/v1/project/" + project + "/stage/" + stage + "/service/" + url.QueryEscape(service) + "/resource/" + url.QueryEscape(resourceURI))
 }
 
@@ -196,6 +330,9 @@ func (r *ResourceHandler) updateResources(uri string, resources []*models.Resour
 }
 
 func (r *ResourceHandler) writeResources(uri string, method string, resources []*models.Resource) (string, error) {
+	if err := validateResourceURIs(resources); err != nil {
+		return "", err
+	}
 
 	copiedResources := make([]*models.Resource, len(resources), len(resources))
 	for i, val := range resources {
@@ -204,36 +341,7 @@ func (r *ResourceHandler) writeResources(uri string, method string, resources []
 	resReq := &resourceRequest{
 		Resources: copiedResources,
 	}
-
-	resourceStr, err := json.Marshal(resReq)
-	if err != nil {
-		return "", err
-	}
-	req, err := http.NewRequest(method, uri, bytes.NewBuffer(resourceStr))
-	req.Header.Set("Content-Type", "application/json")
-	addAuthHeader(req, r)
-
-	resp, err := r.HTTPClient.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	var version models.Version
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return "", err
-	}
-	if !(resp.StatusCode >= 200 && resp.StatusCode < 300) {
-		return "", errors.New(string(body))
-	}
-
-	err = json.Unmarshal(body, &version)
-	if err != nil {
-		return "", err
-	}
-
-	return version.Version, nil
+	return r.sendEncodedResources(uri, method, resReq)
 }
 
 func (r *ResourceHandler) updateResource(uri string, resource *models.Resource) (string, error) {
@@ -241,14 +349,25 @@ func (r *ResourceHandler) updateResource(uri string, resource *models.Resource)
 }
 
 func (r *ResourceHandler) writeResource(uri string, method string, resource *models.Resource) (string, error) {
+	if err := validateResourceURIs([]*models.Resource{resource}); err != nil {
+		return "", err
+	}
 
 	copiedResource := &models.Resource{ResourceURI: resource.ResourceURI, ResourceContent: b64.StdEncoding.EncodeToString([]byte(resource.ResourceContent))}
+	return r.sendEncodedResources(uri, method, copiedResource)
+}
 
-	resourceStr, err := json.Marshal(copiedResource)
+// sendEncodedResources sends the given payload, which must only contain base64 encoded resources, and returns the
+// resulting version
+func (r *ResourceHandler) sendEncodedResources(uri string, method string, payload interface{}) (string, error) {
+	resourceStr, err := json.Marshal(payload)
 	if err != nil {
 		return "", err
 	}
 	req, err := http.NewRequest(method, uri, bytes.NewBuffer(resourceStr))
+	if err != nil {
+		return "", err
+	}
 	req.Header.Set("Content-Type", "application/json")
 	addAuthHeader(req, r)
 
@@ -276,60 +395,145 @@ func (r *ResourceHandler) writeResource(uri string, method string, resource *mod
 	return version.Version, nil
 }
 
+// validateResourceURIs checks whether all resources have a valid resource URI
+func validateResourceURIs(resources []*models.Resource) error {
+	for _, resource := range resources {
+		if resource.ResourceURI == nil {
+			return &InvalidResourceURIError{Rule: ResourceURIRuleNotEmpty}
+		}
+		if err := ValidateResourceURI(*resource.ResourceURI); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *ResourceHandler) getResource(uri string) (*models.Resource, error) {
-	http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	resource, _, err := r.getResourceIfModified(uri, "")
+	return resource, err
+}
+
+// GetResourceIfModified retrieves a resource of the given scope from the configuration service if it does not match
+// the given ETag, and returns the resource together with its current ETag.
+// If the resource has not been modified, ErrResourceNotModified is returned
+func (r *ResourceHandler) GetResourceIfModified(scope ResourceScope, resourceURI string, etag string) (*models.Resource, string, error) {
+	resourceURL, err := r.getResourceURL(scope, resourceURI)
+	if err != nil {
+		return nil, "", err
+	}
+	return r.getResourceIfModified(resourceURL, etag)
+}
+
+func (r *ResourceHandler) getResourceIfModified(uri string, etag string) (*models.Resource, string, error) {
+	disableDefaultTransportTLSVerification()
 	req, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		return nil, "", err
+	}
 	req.Header.Set("Content-Type", "application/json")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
 	addAuthHeader(req, r)
 
 	resp, err := r.HTTPClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer resp.Body.Close()
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, etag, ErrResourceNotModified
+	}
 	if resp.StatusCode == 404 {
 		// need to handle this case differently (e.g. https://github.com/keptn/keptn/issues/1480)
-		return nil, ResourceNotFoundError
+		return nil, "", ResourceNotFoundError
 	}
 	if !(resp.StatusCode >= 200 && resp.StatusCode < 300) {
-		return nil, errors.New(string(body))
+		return nil, "", errors.New(string(body))
 	}
 
 	var resource models.Resource
 	err = json.Unmarshal(body, &resource)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	// decode resource content
 	decodedStr, err := b64.StdEncoding.DecodeString(resource.ResourceContent)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	resource.ResourceContent = string(decodedStr)
 
-	return &resource, nil
+	// the version of the resource is used as ETag if the configuration service does not provide one
+	newETag := resp.Header.Get("ETag")
+	if newETag == "" && resource.Metadata != nil && resource.Metadata.Version != "" {
+		newETag = `"` + resource.Metadata.Version + `"`
+	}
+	return &resource, newETag, nil
 }
 
+// deleteResource sends a DELETE request for the resource without checking the status of the response
 func (r *ResourceHandler) deleteResource(uri string) error {
-	http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	req, err := http.NewRequest("DELETE", uri, nil)
-	req.Header.Set("Content-Type", "application/json")
-	addAuthHeader(req, r)
+	resp, err := r.sendDeleteResourceRequest(uri)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
 
-	resp, err := r.HTTPClient.Do(req)
+// deleteResourceAndCheckStatus deletes the resource and returns ResourceNotFoundError if the resource does not exist,
+// or the error message of the configuration service if the deletion failed
+func (r *ResourceHandler) deleteResourceAndCheckStatus(uri string) error {
+	resp, err := r.sendDeleteResourceRequest(uri)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	return nil
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ResourceNotFoundError
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	respErr := models.Error{}
+	if err := json.Unmarshal(body, &respErr); err != nil || respErr.Message == nil {
+		return fmt.Errorf("could not delete resource: received unexpected response: %d %s", resp.StatusCode, string(body))
+	}
+	return errors.New(*respErr.Message)
+}
+
+func (r *ResourceHandler) sendDeleteResourceRequest(uri string) (*http.Response, error) {
+	disableDefaultTransportTLSVerification()
+	req, err := http.NewRequest("DELETE", uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	addAuthHeader(req, r)
+
+	return r.HTTPClient.Do(req)
+}
+
+// GetAllProjectResources returns a list of all resources.
+func (r *ResourceHandler) GetAllProjectResources(project string) ([]*models.Resource, error) {
+	url, err := url.Parse(r.Scheme + "://" + r.getBaseURL() + "/v1/project/" + project + "/resource")
+	if err != nil {
+		return nil, err
+	}
+	return r.getAllResources(url)
 }
 
 // GetAllStageResources returns a list of all resources.
@@ -353,7 +557,7 @@ func (r *ResourceHandler) GetAllServiceResources(project string, stage string, s
 
 func (r *ResourceHandler) getAllResources(u *url.URL) ([]*models.Resource, error) {
 
-	http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	disableDefaultTransportTLSVerification()
 	resources := []*models.Resource{}
 
 	nextPageKey := ""
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceHandler_GetAllResourcesRequestPaths(t *testing.T) {
+	requestedPaths := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPaths = append(requestedPaths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"resources":[]}`))
+	}))
+	defer ts.Close()
+	rh := NewResourceHandler(ts.URL)
+
+	_, err := rh.GetAllProjectResources("sockshop")
+	require.Nil(t, err)
+	_, err = rh.GetAllStageResources("sockshop", "dev")
+	require.Nil(t, err)
+	_, err = rh.GetAllServiceResources("sockshop", "dev", "carts")
+	require.Nil(t, err)
+	_, err = rh.GetAllResources(ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"})
+	require.Nil(t, err)
+
+	assert.Equal(t, []string{
+		"/v1/project/sockshop/resource",
+		"/v1/project/sockshop/stage/dev/resource",
+		"/v1/project/sockshop/stage/dev/service/carts/resource/",
+		"/v1/project/sockshop/stage/dev/service/carts/resource",
+	}, requestedPaths)
+}
+
+func TestResourceHandler_DeleteResourceIgnoresResponseStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":404,"message":"Resource not found"}`))
+	}))
+	defer ts.Close()
+	rh := NewResourceHandler(ts.URL)
+
+	assert.Nil(t, rh.DeleteProjectResource("sockshop", "slo.yaml"))
+	assert.Nil(t, rh.DeleteStageResource("sockshop", "dev", "slo.yaml"))
+	assert.Nil(t, rh.DeleteServiceResource("sockshop", "dev", "carts", "slo.yaml"))
+}
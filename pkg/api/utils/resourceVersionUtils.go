@@ -0,0 +1,157 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+)
+
+// ErrResourceHistoryNotSupported is returned if the configuration service does not provide the history of resources
+var ErrResourceHistoryNotSupported = errors.New("the configuration service does not support retrieving the history of resources")
+
+// ErrResourceVersionNotSupported is returned if the configuration service does not return the requested version of a resource
+var ErrResourceVersionNotSupported = errors.New("the configuration service does not support retrieving specific versions of resources")
+
+// ResourceVersion is an entry of the version history of a resource
+type ResourceVersion struct {
+	// CommitID is the ID of the commit in the configuration repository which changed the resource
+	CommitID string `json:"commitID"`
+	// Author of the commit
+	Author string `json:"author,omitempty"`
+	// Message of the commit
+	Message string `json:"message,omitempty"`
+	// Date of the commit
+	Date time.Time `json:"date,omitempty"`
+}
+
+type resourceHistory struct {
+	Versions    []*ResourceVersion `json:"versions"`
+	NextPageKey string             `json:"nextPageKey,omitempty"`
+}
+
+// GetResourceAtVersion retrieves the resource of the given scope as it was at the given commit of the configuration repository.
+// The commit ID of the returned content is stored in the Version of its Metadata. If the configuration service ignores the
+// requested commit ID and returns a different version or no version at all, ErrResourceVersionNotSupported is returned
+func (r *ResourceHandler) GetResourceAtVersion(scope ResourceScope, resourceURI string, commitID string) (*models.Resource, error) {
+	resourceURL, err := r.getResourceURL(scope, resourceURI)
+	if err != nil {
+		return nil, err
+	}
+	if commitID == "" {
+		return nil, errors.New("commit ID must not be empty")
+	}
+	resource, err := r.getResource(resourceURL + "?gitCommitID=" + url.QueryEscape(commitID))
+	if err != nil {
+		return nil, err
+	}
+	// without the version of the returned content, it can not be told apart from the latest version
+	if resource.Metadata == nil || resource.Metadata.Version == "" || !isSameCommit(resource.Metadata.Version, commitID) {
+		return nil, ErrResourceVersionNotSupported
+	}
+	return resource, nil
+}
+
+// GetResourceHistory returns the versions of the resource of the given scope, as provided by the configuration service.
+// If the configuration service does not provide the history of resources, ErrResourceHistoryNotSupported is returned.
+// If the resource does not exist, ResourceNotFoundError is returned
+func (r *ResourceHandler) GetResourceHistory(scope ResourceScope, resourceURI string) ([]*ResourceVersion, error) {
+	resourceURL, err := r.getResourceURL(scope, resourceURI)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(resourceURL + "/history")
+	if err != nil {
+		return nil, err
+	}
+
+	disableDefaultTransportTLSVerification()
+	versions := []*ResourceVersion{}
+	nextPageKey := ""
+	for {
+		if nextPageKey != "" {
+			q := u.Query()
+			q.Set("nextPageKey", nextPageKey)
+			u.RawQuery = q.Encode()
+		}
+		req, err := http.NewRequest("GET", u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		addAuthHeader(req, r)
+
+		resp, err := r.HTTPClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented:
+			return nil, ErrResourceHistoryNotSupported
+		case resp.StatusCode == http.StatusNotFound && isUnknownRouteResponse(body):
+			return nil, ErrResourceHistoryNotSupported
+		case resp.StatusCode == http.StatusNotFound:
+			return nil, ResourceNotFoundError
+		case resp.StatusCode < 200 || resp.StatusCode >= 300:
+			respErr := models.Error{}
+			if err := json.Unmarshal(body, &respErr); err != nil || respErr.Message == nil {
+				return nil, fmt.Errorf("could not retrieve history of resource %s: %s", resourceURI, string(body))
+			}
+			return nil, errors.New(*respErr.Message)
+		}
+
+		received := resourceHistory{}
+		if err := json.Unmarshal(body, &received); err != nil {
+			return nil, err
+		}
+		versions = append(versions, received.Versions...)
+		if received.NextPageKey == "" || received.NextPageKey == "0" {
+			break
+		}
+		nextPageKey = received.NextPageKey
+	}
+	return versions, nil
+}
+
+// isUnknownRouteResponse checks whether the body of a 404 response indicates that the requested route does not exist,
+// rather than the requested resource. This is the case if the body is not an error object of the configuration service,
+// e.g. "404 page not found", or if it is the error returned by the API for unknown paths
+func isUnknownRouteResponse(body []byte) bool {
+	respErr := models.Error{}
+	if err := json.Unmarshal(body, &respErr); err != nil || respErr.Message == nil {
+		return true
+	}
+	message := *respErr.Message
+	return strings.HasPrefix(message, "path ") && strings.HasSuffix(message, " was not found")
+}
+
+// RestoreResourceVersion writes the content the resource of the given scope had at the given commit as its new content
+// and returns the resulting version
+func (r *ResourceHandler) RestoreResourceVersion(scope ResourceScope, resourceURI string, commitID string) (string, error) {
+	resource, err := r.GetResourceAtVersion(scope, resourceURI, commitID)
+	if err != nil {
+		return "", err
+	}
+	uri := resourceURI
+	return r.updateResource(
+		r.getScopeURL(scope)+"/resource/"+url.QueryEscape(resourceURI),
+		&models.Resource{ResourceURI: &uri, ResourceContent: resource.ResourceContent},
+	)
+}
+
+// isSameCommit checks whether the commit IDs refer to the same commit, also considering abbreviated commit IDs
+func isSameCommit(commitID1, commitID2 string) bool {
+	return strings.HasPrefix(commitID1, commitID2) || strings.HasPrefix(commitID2, commitID1)
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	b64 "encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/go-utils/pkg/common/strutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sloResourcePath = "/v1/project/sockshop/stage/dev/service/carts/resource/slo.yaml"
+
+func newVersionedResourceServer(t *testing.T, versions map[string]string, latest string) (*httptest.Server, *[]string) {
+	written := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == sloResourcePath && r.Method == http.MethodPut:
+			body, _ := ioutil.ReadAll(r.Body)
+			resource := models.Resource{}
+			require.Nil(t, json.Unmarshal(body, &resource))
+			content, _ := b64.StdEncoding.DecodeString(resource.ResourceContent)
+			written = append(written, string(content))
+			w.Write([]byte(`{"version":"restored"}`))
+		case r.URL.Path == sloResourcePath:
+			commitID := r.URL.Query().Get("gitCommitID")
+			if commitID == "" {
+				commitID = latest
+			}
+			content, ok := versions[commitID]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			body, _ := json.Marshal(models.Resource{
+				ResourceURI:     strutils.Stringp("slo.yaml"),
+				ResourceContent: b64.StdEncoding.EncodeToString([]byte(content)),
+				Metadata:        &models.Version{Branch: "dev", Version: commitID},
+			})
+			w.Write(body)
+		case r.URL.Path == sloResourcePath+"/history" && r.URL.Query().Get("nextPageKey") == "":
+			w.Write([]byte(`{"versions":[{"commitID":"c3","message":"update slo"}],"nextPageKey":"1"}`))
+		case r.URL.Path == sloResourcePath+"/history":
+			w.Write([]byte(`{"versions":[{"commitID":"c2"},{"commitID":"c1","author":"keptn"}]}`))
+		case r.URL.Path == "/v1/project/sockshop/stage/dev/service/carts/resource/missing.yaml/history":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"Resource not found"}`))
+		case r.URL.Path == "/v1/project/sockshop/stage/dev/resource/slo.yaml/history":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"path /v1/project/sockshop/stage/dev/resource/slo.yaml/history was not found"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return ts, &written
+}
+
+func TestResourceHandler_GetResourceAtVersion(t *testing.T) {
+	ts, _ := newVersionedResourceServer(t, map[string]string{"c1": "objectives: []", "c2": "objectives: [response_time]"}, "c2")
+	defer ts.Close()
+	rh := NewResourceHandler(ts.URL)
+	scope := ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"}
+
+	resource, err := rh.GetResourceAtVersion(scope, "slo.yaml", "c1")
+	require.Nil(t, err)
+	assert.Equal(t, "objectives: []", resource.ResourceContent)
+	assert.Equal(t, "c1", resource.Metadata.Version)
+
+	_, err = rh.GetResourceAtVersion(scope, "slo.yaml", "unknown")
+	assert.Equal(t, ResourceNotFoundError, err)
+
+	_, err = rh.GetResourceAtVersion(scope, "slo.yaml", "")
+	assert.NotNil(t, err)
+}
+
+func TestResourceHandler_GetResourceAtVersionNotSupported(t *testing.T) {
+	// the server ignores the requested commit ID and always returns the latest version
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"resourceURI":"slo.yaml","resourceContent":"","metadata":{"version":"c2"}}`))
+	}))
+	defer ts.Close()
+	rh := NewResourceHandler(ts.URL)
+
+	_, err := rh.GetResourceAtVersion(ResourceScope{Project: "sockshop"}, "slo.yaml", "c1")
+	assert.Equal(t, ErrResourceVersionNotSupported, err)
+
+	resource, err := rh.GetResourceAtVersion(ResourceScope{Project: "sockshop"}, "slo.yaml", "c2")
+	require.Nil(t, err)
+	assert.Equal(t, "c2", resource.Metadata.Version)
+}
+
+func TestResourceHandler_GetResourceAtVersionWithoutVersion(t *testing.T) {
+	// the server ignores the requested commit ID and does not return the version of the content
+	for _, body := range []string{
+		`{"resourceURI":"slo.yaml","resourceContent":""}`,
+		`{"resourceURI":"slo.yaml","resourceContent":"","metadata":{"branch":"master"}}`,
+	} {
+		body := body
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		}))
+		rh := NewResourceHandler(ts.URL)
+
+		_, err := rh.GetResourceAtVersion(ResourceScope{Project: "sockshop"}, "slo.yaml", "c1")
+		assert.Equal(t, ErrResourceVersionNotSupported, err)
+		ts.Close()
+	}
+}
+
+func TestResourceHandler_GetResourceHistory(t *testing.T) {
+	ts, _ := newVersionedResourceServer(t, map[string]string{}, "c3")
+	defer ts.Close()
+	rh := NewResourceHandler(ts.URL)
+
+	versions, err := rh.GetResourceHistory(ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"}, "slo.yaml")
+	require.Nil(t, err)
+	require.Len(t, versions, 3)
+	assert.Equal(t, "c3", versions[0].CommitID)
+	assert.Equal(t, "update slo", versions[0].Message)
+	assert.Equal(t, "c1", versions[2].CommitID)
+	assert.Equal(t, "keptn", versions[2].Author)
+
+	_, err = rh.GetResourceHistory(ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"}, "missing.yaml")
+	assert.Equal(t, ResourceNotFoundError, err)
+
+	_, err = rh.GetResourceHistory(ResourceScope{Project: "sockshop", Stage: "dev"}, "slo.yaml")
+	assert.Equal(t, ErrResourceHistoryNotSupported, err)
+
+	_, err = rh.GetResourceHistory(ResourceScope{Project: "sockshop"}, "slo.yaml")
+	assert.Equal(t, ErrResourceHistoryNotSupported, err)
+}
+
+func TestResourceHandler_RestoreResourceVersion(t *testing.T) {
+	ts, written := newVersionedResourceServer(t, map[string]string{"c1": "objectives: []", "c2": "objectives: [response_time]"}, "c2")
+	defer ts.Close()
+	rh := NewResourceHandler(ts.URL)
+
+	version, err := rh.RestoreResourceVersion(ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"}, "slo.yaml", "c1")
+	require.Nil(t, err)
+	assert.Equal(t, "restored", version)
+	assert.Equal(t, []string{"objectives: []"}, *written)
+}
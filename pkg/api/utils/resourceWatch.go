@@ -0,0 +1,207 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+)
+
+// ResourceChangeType describes how a watched resource has changed
+type ResourceChangeType string
+
+const (
+	// ResourceCreated indicates that a resource has been created
+	ResourceCreated ResourceChangeType = "created"
+	// ResourceUpdated indicates that the content of a resource has been updated
+	ResourceUpdated ResourceChangeType = "updated"
+	// ResourceDeleted indicates that a resource has been deleted
+	ResourceDeleted ResourceChangeType = "deleted"
+)
+
+// WatchedResource identifies a resource watched by the ResourceWatcher
+type WatchedResource struct {
+	Scope       ResourceScope
+	ResourceURI string
+}
+
+// NewWatchedResources returns the given resource URIs for all levels of the scope, e.g. the service, stage and
+// project level for a scope referring to a service
+func NewWatchedResources(scope ResourceScope, resourceURIs ...string) []WatchedResource {
+	resources := []WatchedResource{}
+	for _, level := range scope.Hierarchy() {
+		for _, resourceURI := range resourceURIs {
+			resources = append(resources, WatchedResource{Scope: level, ResourceURI: resourceURI})
+		}
+	}
+	return resources
+}
+
+// ResourceChange is a change of a watched resource
+type ResourceChange struct {
+	WatchedResource
+	Type ResourceChangeType
+	// Resource is the current resource. It is nil if the resource has been deleted
+	Resource *models.Resource
+	// PreviousVersion is the version of the resource before the change, if known
+	PreviousVersion string
+}
+
+type watchedResourceState struct {
+	exists  bool
+	version string
+	hash    string
+}
+
+// ResourceWatcher implements the logic to poll resources and notify the client about changes
+type ResourceWatcher struct {
+	reader    ResourceReader
+	resources []WatchedResource
+	states    map[WatchedResource]watchedResourceState
+	interval  time.Duration
+	timeout   time.Duration
+}
+
+const defaultResourceWatchInterval = 10 * time.Second
+
+// NewResourceWatcher creates a new resource watcher for the given resources with the given options
+func NewResourceWatcher(reader ResourceReader, resources []WatchedResource, opts ...ResourceWatcherOption) *ResourceWatcher {
+	rw := &ResourceWatcher{
+		reader:    reader,
+		resources: resources,
+		states:    map[WatchedResource]watchedResourceState{},
+		interval:  defaultResourceWatchInterval,
+	}
+
+	for _, opt := range opts {
+		opt(rw)
+	}
+
+	return rw
+}
+
+// ResourceWatcherOption can be used to configure the ResourceWatcher
+type ResourceWatcherOption func(*ResourceWatcher)
+
+// WithResourceWatchInterval configures the ResourceWatcher to use a custom delay between each poll
+// You can use this to overwrite the default which is 10 * time.Second
+func WithResourceWatchInterval(interval time.Duration) ResourceWatcherOption {
+	return func(rw *ResourceWatcher) {
+		rw.interval = interval
+	}
+}
+
+// WithResourceWatchTimeout configures the ResourceWatcher to use a custom timeout specifying
+// after which duration after starting the watch the watcher shall stop
+func WithResourceWatchTimeout(duration time.Duration) ResourceWatcherOption {
+	return func(rw *ResourceWatcher) {
+		rw.timeout = duration
+	}
+}
+
+// Watch starts the watch loop and returns a channel to get the changes of the resources as well as a context.CancelFunc
+// in order to stop the watch routine. The first poll determines the initial state of the resources and does not result
+// in any notifications. Afterwards, only non-empty lists of changes are sent
+func (rw *ResourceWatcher) Watch(ctx context.Context) (<-chan []ResourceChange, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	ch := make(chan []ResourceChange)
+	go rw.poll(ctx, cancel, ch)
+	return ch, cancel
+}
+
+func (rw *ResourceWatcher) poll(ctx context.Context, cancel context.CancelFunc, ch chan<- []ResourceChange) {
+	ticker := time.NewTicker(rw.interval)
+	var timeout <-chan time.Time
+	if rw.timeout > 0 {
+		timer := time.NewTimer(rw.timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	defer func() {
+		cancel()
+		ticker.Stop()
+		close(ch)
+	}()
+
+	// the initial state is determined immediately, because a time.Ticker cannot be configured to emit a tick immediately
+	rw.queryChanges()
+	for {
+		select {
+		// Query again once we receive a next tick
+		case <-ticker.C:
+			if changes := rw.queryChanges(); len(changes) > 0 {
+				select {
+				case ch <- changes:
+				case <-ctx.Done():
+					return
+				}
+			}
+		// Break out once we reach a timeout
+		case <-timeout:
+			return
+		// Break out once the user cancels via the context
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// queryChanges retrieves the watched resources and returns the changes compared to the previous query
+func (rw *ResourceWatcher) queryChanges() []ResourceChange {
+	changes := []ResourceChange{}
+	for _, watched := range rw.resources {
+		resource, err := rw.reader.GetResource(watched.Scope, watched.ResourceURI)
+		if err != nil && err != ResourceNotFoundError {
+			// keep the previous state, the resource is checked again during the next poll
+			log.Printf("Unable to fetch resource %s of %s: %s", watched.ResourceURI, watched.Scope, err.Error())
+			continue
+		}
+
+		previous, known := rw.states[watched]
+		current := watchedResourceState{}
+		if err == nil {
+			current = watchedResourceState{exists: true, version: resourceVersion(resource), hash: hashResourceContent(resource)}
+		}
+		rw.states[watched] = current
+		if !known {
+			continue
+		}
+
+		change := ResourceChange{WatchedResource: watched, Resource: resource, PreviousVersion: previous.version}
+		switch {
+		case !previous.exists && current.exists:
+			change.Type = ResourceCreated
+		case previous.exists && !current.exists:
+			change.Type = ResourceDeleted
+		case current.exists && !isSameResourceState(previous, current):
+			change.Type = ResourceUpdated
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// isSameResourceState compares the versions of the resource if available and the hashes of their content otherwise
+func isSameResourceState(previous, current watchedResourceState) bool {
+	if previous.version != "" && previous.version == current.version {
+		return true
+	}
+	return previous.hash == current.hash
+}
+
+func resourceVersion(resource *models.Resource) string {
+	if resource.Metadata == nil {
+		return ""
+	}
+	return resource.Metadata.Version
+}
+
+func hashResourceContent(resource *models.Resource) string {
+	hash := sha256.Sum256([]byte(resource.ResourceContent))
+	return hex.EncodeToString(hash[:])
+}
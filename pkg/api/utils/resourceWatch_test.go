@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// notifyingResourceReader closes the polled channel once the given number of resources has been retrieved
+type notifyingResourceReader struct {
+	ResourceReader
+	lock   sync.Mutex
+	gets   int
+	after  int
+	polled chan struct{}
+	err    error
+}
+
+func (n *notifyingResourceReader) GetResource(scope ResourceScope, resourceURI string) (*models.Resource, error) {
+	n.lock.Lock()
+	n.gets++
+	if n.gets == n.after {
+		close(n.polled)
+	}
+	err := n.err
+	n.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return n.ResourceReader.GetResource(scope, resourceURI)
+}
+
+func TestNewWatchedResources(t *testing.T) {
+	resources := NewWatchedResources(serviceScope, "slo.yaml", "sli.yaml")
+	assert.Equal(t, []WatchedResource{
+		{Scope: serviceScope, ResourceURI: "slo.yaml"},
+		{Scope: serviceScope, ResourceURI: "sli.yaml"},
+		{Scope: stageScope, ResourceURI: "slo.yaml"},
+		{Scope: stageScope, ResourceURI: "sli.yaml"},
+		{Scope: projectScope, ResourceURI: "slo.yaml"},
+		{Scope: projectScope, ResourceURI: "sli.yaml"},
+	}, resources)
+}
+
+func TestResourceWatcher_queryChanges(t *testing.T) {
+	store := newTestResourceStore()
+	watcher := NewResourceWatcher(store, NewWatchedResources(serviceScope, "slo.yaml", "sli.yaml"))
+
+	assert.Empty(t, watcher.queryChanges())
+	assert.Empty(t, watcher.queryChanges())
+
+	store.SetResource(stageScope, "slo.yaml", "updated-slo")
+	store.SetResource(serviceScope, "sli.yaml", "indicators: {}")
+	store.DeleteResource(projectScope, "slo.yaml")
+
+	changes := watcher.queryChanges()
+	require.Len(t, changes, 3)
+	assert.Equal(t, WatchedResource{Scope: serviceScope, ResourceURI: "sli.yaml"}, changes[0].WatchedResource)
+	assert.Equal(t, ResourceCreated, changes[0].Type)
+	assert.Equal(t, "indicators: {}", changes[0].Resource.ResourceContent)
+	assert.Equal(t, WatchedResource{Scope: stageScope, ResourceURI: "slo.yaml"}, changes[1].WatchedResource)
+	assert.Equal(t, ResourceUpdated, changes[1].Type)
+	assert.Equal(t, "updated-slo", changes[1].Resource.ResourceContent)
+	assert.Equal(t, WatchedResource{Scope: projectScope, ResourceURI: "slo.yaml"}, changes[2].WatchedResource)
+	assert.Equal(t, ResourceDeleted, changes[2].Type)
+	assert.Nil(t, changes[2].Resource)
+
+	// setting the same content again is not a change
+	store.SetResource(stageScope, "slo.yaml", "updated-slo")
+	assert.Empty(t, watcher.queryChanges())
+}
+
+func TestResourceWatcher_queryChangesKeepsStateOnErrors(t *testing.T) {
+	store := newTestResourceStore()
+	reader := &notifyingResourceReader{ResourceReader: store, polled: make(chan struct{})}
+	watcher := NewResourceWatcher(reader, []WatchedResource{{Scope: stageScope, ResourceURI: "slo.yaml"}})
+	assert.Empty(t, watcher.queryChanges())
+
+	reader.err = errors.New("configuration service unavailable")
+	assert.Empty(t, watcher.queryChanges())
+
+	reader.err = nil
+	assert.Empty(t, watcher.queryChanges())
+}
+
+func TestResourceWatcher_Watch(t *testing.T) {
+	store := newTestResourceStore()
+	resources := NewWatchedResources(serviceScope, "slo.yaml")
+	reader := &notifyingResourceReader{ResourceReader: store, after: len(resources), polled: make(chan struct{})}
+	watcher := NewResourceWatcher(reader, resources, WithResourceWatchInterval(5*time.Millisecond))
+
+	changes, cancel := watcher.Watch(context.Background())
+	<-reader.polled
+	store.SetResource(serviceScope, "slo.yaml", "service-slo")
+
+	select {
+	case received := <-changes:
+		require.Len(t, received, 1)
+		assert.Equal(t, ResourceCreated, received[0].Type)
+		assert.Equal(t, serviceScope, received[0].Scope)
+	case <-time.After(5 * time.Second):
+		t.Fatal("did not receive changes")
+	}
+
+	cancel()
+	for range changes {
+		// drain changes which may have been queried before cancelling
+	}
+}
+
+func TestResourceWatcher_WatchTimeout(t *testing.T) {
+	watcher := NewResourceWatcher(newTestResourceStore(), NewWatchedResources(stageScope, "slo.yaml"),
+		WithResourceWatchInterval(5*time.Millisecond),
+		WithResourceWatchTimeout(20*time.Millisecond),
+	)
+	changes, _ := watcher.Watch(context.Background())
+
+	select {
+	case _, ok := <-changes:
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("watcher did not stop")
+	}
+}
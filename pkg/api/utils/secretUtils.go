@@ -3,15 +3,36 @@ package api
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/keptn/go-utils/pkg/api/models"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"regexp"
 	"strings"
 )
 
 const secretServiceBaseURL = "secrets"
 const v1SecretPath = "/v1/secret"
 
+// DefaultSecretScope is the scope of secrets which are created without a scope
+const DefaultSecretScope = "keptn-default"
+
+const maxSecretNameLength = 253
+const maxSecretKeyLength = 253
+
+// secretNameRegex matches DNS subdomain names as required by Kubernetes for the names of secrets
+var secretNameRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+// secretKeyRegex matches the keys allowed by Kubernetes for the data of secrets
+var secretKeyRegex = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+// ErrSecretAlreadyExists is returned if a secret with the same name already exists in the scope
+var ErrSecretAlreadyExists = errors.New("secret already exists")
+
+// ErrSecretNotFound is returned if the secret does not exist in the scope
+var ErrSecretNotFound = errors.New("secret not found")
+
 //go:generate moq -pkg utils_mock -skip-ensure -out ./fake/secret_handler_mock.go . SecretHandlerInterface
 type SecretHandlerInterface interface {
 	CreateSecret(secret models.Secret) error
@@ -20,6 +41,15 @@ type SecretHandlerInterface interface {
 	GetSecrets() (*models.GetSecretsResponse, error)
 }
 
+//go:generate moq -pkg utils_mock -skip-ensure -out ./fake/secret_lookup_mock.go . SecretLookupInterface
+
+// SecretLookupInterface looks up the metadata of secrets by their scope and name.
+// It is implemented by the SecretHandler in addition to the SecretHandlerInterface
+type SecretLookupInterface interface {
+	GetSecret(secretName, secretScope string) (*models.SecretMetadata, error)
+	GetSecretsByScope(secretScope string) ([]models.SecretMetadata, error)
+}
+
 // SecretHandler handles services
 type SecretHandler struct {
 	BaseURL    string
@@ -87,41 +117,97 @@ func (s *SecretHandler) getHTTPClient() *http.Client {
 	return s.HTTPClient
 }
 
-// CreateSecret creates a new secret
+// CreateSecret creates a new secret.
+// If a secret with the same name already exists in the scope, an error wrapping ErrSecretAlreadyExists is returned
 func (s *SecretHandler) CreateSecret(secret models.Secret) error {
+	if err := ValidateSecret(secret); err != nil {
+		return err
+	}
 	body, err := json.Marshal(secret)
 	if err != nil {
 		return err
 	}
 	_, errObj := post(s.Scheme+"://"+s.BaseURL+v1SecretPath, body, s)
 	if errObj != nil {
-		return errors.New(errObj.GetMessage())
+		return getSecretError(errObj)
 	}
 	return nil
 }
 
-// UpdateSecret creates a new secret
+// UpdateSecret updates an existing secret.
+// If the secret does not exist in the scope, an error wrapping ErrSecretNotFound is returned
 func (s *SecretHandler) UpdateSecret(secret models.Secret) error {
+	if err := ValidateSecret(secret); err != nil {
+		return err
+	}
 	body, err := json.Marshal(secret)
 	if err != nil {
 		return err
 	}
 	_, errObj := put(s.Scheme+"://"+s.BaseURL+v1SecretPath, body, s)
 	if errObj != nil {
-		return errors.New(errObj.GetMessage())
+		return getSecretError(errObj)
 	}
 	return nil
 }
 
-// DeleteSecret deletes a secret
+// DeleteSecret deletes a secret.
+// If the secret does not exist in the scope, an error wrapping ErrSecretNotFound is returned
 func (s *SecretHandler) DeleteSecret(secretName, secretScope string) error {
-	_, err := delete(s.Scheme+"://"+s.BaseURL+v1SecretPath+"?name="+secretName+"&scope="+secretScope, s)
+	if err := ValidateSecretName(secretName); err != nil {
+		return err
+	}
+	query := url.Values{}
+	query.Set("name", secretName)
+	query.Set("scope", secretScope)
+	_, err := deleteRequest(s.Scheme+"://"+s.BaseURL+v1SecretPath+"?"+query.Encode(), s)
 	if err != nil {
-		return errors.New(err.GetMessage())
+		return getSecretError(err)
 	}
 	return nil
 }
 
+// GetSecret returns the metadata of the secret with the given name in the given scope, including the keys of its data
+// but not their values. If the scope is empty, DefaultSecretScope is used.
+// If the secret does not exist in the scope, ErrSecretNotFound is returned
+func (s *SecretHandler) GetSecret(secretName, secretScope string) (*models.SecretMetadata, error) {
+	if err := ValidateSecretName(secretName); err != nil {
+		return nil, err
+	}
+	secrets, err := s.GetSecretsByScope(secretScope)
+	if err != nil {
+		return nil, err
+	}
+	for i := range secrets {
+		if secrets[i].Name != nil && *secrets[i].Name == secretName {
+			return &secrets[i], nil
+		}
+	}
+	return nil, ErrSecretNotFound
+}
+
+// GetSecretsByScope returns the secrets of the given scope. If the scope is empty, DefaultSecretScope is used
+func (s *SecretHandler) GetSecretsByScope(secretScope string) ([]models.SecretMetadata, error) {
+	if secretScope == "" {
+		secretScope = DefaultSecretScope
+	}
+	result, err := s.GetSecrets()
+	if err != nil {
+		return nil, err
+	}
+	secrets := []models.SecretMetadata{}
+	for _, secret := range result.Secrets {
+		scope := DefaultSecretScope
+		if secret.Scope != nil && *secret.Scope != "" {
+			scope = *secret.Scope
+		}
+		if scope == secretScope {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets, nil
+}
+
 // GetSecrets returns a list of created secrets
 func (s *SecretHandler) GetSecrets() (*models.GetSecretsResponse, error) {
 	req, err := http.NewRequest("GET", s.Scheme+"://"+s.BaseURL+v1SecretPath, nil)
@@ -155,3 +241,59 @@ func (s *SecretHandler) GetSecrets() (*models.GetSecretsResponse, error) {
 	}
 	return result, nil
 }
+
+// ValidateSecret checks whether the name of the secret and the keys of its data are valid according to the naming
+// rules of Kubernetes
+func ValidateSecret(secret models.Secret) error {
+	if secret.Name == nil {
+		return errors.New("secret name must not be empty")
+	}
+	if err := ValidateSecretName(*secret.Name); err != nil {
+		return err
+	}
+	if len(secret.Data) == 0 {
+		return fmt.Errorf("secret %s must contain data", *secret.Name)
+	}
+	for key := range secret.Data {
+		if err := ValidateSecretKey(key); err != nil {
+			return fmt.Errorf("invalid data of secret %s: %w", *secret.Name, err)
+		}
+	}
+	return nil
+}
+
+// ValidateSecretName checks whether the name is a valid name for a secret, i.e. a DNS subdomain consisting of at most
+// 253 lower case alphanumeric characters, '-' or '.', starting and ending with an alphanumeric character
+func ValidateSecretName(name string) error {
+	if name == "" {
+		return errors.New("secret name must not be empty")
+	}
+	if len(name) > maxSecretNameLength || !secretNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q: must consist of at most %d lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character", name, maxSecretNameLength)
+	}
+	return nil
+}
+
+// ValidateSecretKey checks whether the key is a valid key for the data of a secret, i.e. it consists of at most
+// 253 alphanumeric characters, '-', '_' or '.'
+func ValidateSecretKey(key string) error {
+	if key == "" {
+		return errors.New("secret key must not be empty")
+	}
+	if key == "." || key == ".." || len(key) > maxSecretKeyLength || !secretKeyRegex.MatchString(key) {
+		return fmt.Errorf("invalid secret key %q: must consist of at most %d alphanumeric characters, '-', '_' or '.'", key, maxSecretKeyLength)
+	}
+	return nil
+}
+
+// getSecretError converts the error returned by the secret service, wrapping ErrSecretAlreadyExists or
+// ErrSecretNotFound according to the status code of the error
+func getSecretError(errObj *models.Error) error {
+	switch errObj.Code {
+	case http.StatusConflict:
+		return fmt.Errorf("%w: %s", ErrSecretAlreadyExists, errObj.GetMessage())
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrSecretNotFound, errObj.GetMessage())
+	}
+	return errors.New(errObj.GetMessage())
+}
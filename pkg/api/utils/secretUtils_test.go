@@ -0,0 +1,133 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ SecretHandlerInterface = &SecretHandler{}
+var _ SecretLookupInterface = &SecretHandler{}
+
+func TestSecretHandlerGetSecret(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"secrets": [
+			{"name": "dynatrace", "scope": "keptn-default", "keys": ["DT_API_TOKEN", "DT_TENANT"]},
+			{"name": "webhook", "keys": ["token"]},
+			{"name": "dynatrace", "scope": "keptn-webhook-service", "keys": ["token"]}
+		]}`))
+	}))
+	defer ts.Close()
+
+	handler := NewSecretHandler(ts.URL)
+
+	secret, err := handler.GetSecret("dynatrace", "")
+	require.Nil(t, err)
+	assert.Equal(t, []string{"DT_API_TOKEN", "DT_TENANT"}, secret.Keys)
+
+	secret, err = handler.GetSecret("dynatrace", "keptn-webhook-service")
+	require.Nil(t, err)
+	assert.Equal(t, []string{"token"}, secret.Keys)
+
+	_, err = handler.GetSecret("webhook", "keptn-webhook-service")
+	assert.Equal(t, ErrSecretNotFound, err)
+
+	_, err = handler.GetSecret("Invalid_Name", "")
+	assert.NotNil(t, err)
+
+	secrets, err := handler.GetSecretsByScope(DefaultSecretScope)
+	require.Nil(t, err)
+	require.Len(t, secrets, 2)
+	assert.Equal(t, "dynatrace", *secrets[0].Name)
+	assert.Equal(t, "webhook", *secrets[1].Name)
+}
+
+func TestSecretHandlerErrors(t *testing.T) {
+	var receivedQuery string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodPost:
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"message": "secret already exists"}`))
+		case http.MethodDelete:
+			receivedQuery = r.URL.RawQuery
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code": 404, "message": "secret not found"}`))
+		}
+	}))
+	defer ts.Close()
+
+	handler := NewSecretHandler(ts.URL)
+
+	err := handler.CreateSecret(models.Secret{
+		Data:           map[string]string{"token": "value"},
+		SecretMetadata: models.SecretMetadata{Name: stringp("my-secret"), Scope: stringp(DefaultSecretScope)},
+	})
+	assert.True(t, errors.Is(err, ErrSecretAlreadyExists))
+
+	err = handler.DeleteSecret("my-secret", "my-scope&name=other")
+	assert.True(t, errors.Is(err, ErrSecretNotFound))
+	assert.Equal(t, "name=my-secret&scope=my-scope%26name%3Dother", receivedQuery)
+}
+
+func TestValidateSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  models.Secret
+		wantErr bool
+	}{
+		{
+			name:   "valid secret",
+			secret: models.Secret{Data: map[string]string{"DT_API_TOKEN": "x", "tls.crt": "y"}, SecretMetadata: models.SecretMetadata{Name: stringp("dynatrace.keptn-1")}},
+		},
+		{
+			name:    "missing name",
+			secret:  models.Secret{Data: map[string]string{"token": "x"}},
+			wantErr: true,
+		},
+		{
+			name:    "upper case name",
+			secret:  models.Secret{Data: map[string]string{"token": "x"}, SecretMetadata: models.SecretMetadata{Name: stringp("Dynatrace")}},
+			wantErr: true,
+		},
+		{
+			name:    "name ending with dash",
+			secret:  models.Secret{Data: map[string]string{"token": "x"}, SecretMetadata: models.SecretMetadata{Name: stringp("dynatrace-")}},
+			wantErr: true,
+		},
+		{
+			name:    "name too long",
+			secret:  models.Secret{Data: map[string]string{"token": "x"}, SecretMetadata: models.SecretMetadata{Name: stringp(strings.Repeat("a", 254))}},
+			wantErr: true,
+		},
+		{
+			name:    "missing data",
+			secret:  models.Secret{SecretMetadata: models.SecretMetadata{Name: stringp("dynatrace")}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid key",
+			secret:  models.Secret{Data: map[string]string{"api token": "x"}, SecretMetadata: models.SecretMetadata{Name: stringp("dynatrace")}},
+			wantErr: true,
+		},
+		{
+			name:    "dot key",
+			secret:  models.Secret{Data: map[string]string{"..": "x"}, SecretMetadata: models.SecretMetadata{Name: stringp("dynatrace")}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSecret(tt.secret)
+			assert.Equal(t, tt.wantErr, err != nil, "ValidateSecret() error = %v", err)
+		})
+	}
+}
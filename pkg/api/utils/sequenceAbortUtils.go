@@ -0,0 +1,242 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+)
+
+const defaultSequenceAbortConcurrency = 5
+
+// OpenSequence is a sequence which has at least one open .triggered event in a stage
+type OpenSequence struct {
+	KeptnContext string
+	Project      string
+	Stage        string
+	Service      string
+	Labels       map[string]string
+	// EventTypes contains the types of the open .triggered events of the sequence
+	EventTypes []string
+	// Time is the time of the oldest open .triggered event of the sequence
+	Time time.Time
+}
+
+// SequenceAbortResult contains the outcome of aborting a single sequence
+type SequenceAbortResult struct {
+	Sequence OpenSequence
+	// Aborted is true if the abort request has been sent successfully. It is always false in dry-run mode
+	Aborted bool
+	// Error is set if the sequence could not be aborted
+	Error error
+}
+
+// AbortSequencesParams contains the criteria used to select the open sequences to abort
+type AbortSequencesParams struct {
+	// Project is the project the sequences belong to (required)
+	Project string
+	// Stage optionally restricts the selection to a stage
+	Stage string
+	// Service optionally restricts the selection to a service
+	Service string
+	// EventTypes contains the .triggered event types used to look up open sequences (required),
+	// e.g. "sh.keptn.event.deployment.triggered"
+	EventTypes []string
+	// OlderThan optionally restricts the selection to sequences whose oldest open event is older than the given duration
+	OlderThan time.Duration
+	// LabelSelector optionally restricts the selection to sequences whose labels match the selector, using the syntax of
+	// Kubernetes label selectors, e.g. "rollout=broken,team in (a,b),!canary"
+	LabelSelector string
+	// Concurrency is the maximum number of abort requests sent in parallel. Defaults to 5
+	Concurrency int
+	// DryRun only reports the selected sequences without aborting them
+	DryRun bool
+}
+
+// Validate checks whether the required parameters are set
+func (p *AbortSequencesParams) Validate() error {
+	var errMsg []string
+	if p.Project == "" {
+		errMsg = append(errMsg, "project parameter not set")
+	}
+	if len(p.EventTypes) == 0 {
+		errMsg = append(errMsg, "event types parameter not set")
+	}
+	if p.Concurrency < 0 {
+		errMsg = append(errMsg, "concurrency must not be negative")
+	}
+	if _, err := parseLabelSelector(p.LabelSelector); err != nil {
+		errMsg = append(errMsg, err.Error())
+	}
+	if len(errMsg) > 0 {
+		return fmt.Errorf("failed to validate abort sequences parameters: %s", strings.Join(errMsg, ","))
+	}
+	return nil
+}
+
+// SequenceAborter aborts multiple open sequences matching a set of selection criteria
+type SequenceAborter struct {
+	shipyardControlHandler ShipyardControlInterface
+	sequenceControlHandler SequenceControlInterface
+	now                    func() time.Time
+}
+
+// NewSequenceAborter creates a new SequenceAborter that looks up open sequences via the given ShipyardControlInterface
+// and aborts them via the given SequenceControlInterface
+func NewSequenceAborter(shipyardControlHandler ShipyardControlInterface, sequenceControlHandler SequenceControlInterface) *SequenceAborter {
+	return &SequenceAborter{
+		shipyardControlHandler: shipyardControlHandler,
+		sequenceControlHandler: sequenceControlHandler,
+		now:                    time.Now,
+	}
+}
+
+type sequenceEventData struct {
+	Project string            `json:"project"`
+	Stage   string            `json:"stage"`
+	Service string            `json:"service"`
+	Labels  map[string]string `json:"labels"`
+}
+
+// GetOpenSequences returns all open sequences matching the given parameters, ordered from oldest to newest
+func (s *SequenceAborter) GetOpenSequences(params AbortSequencesParams) ([]*OpenSequence, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	selector, err := parseLabelSelector(params.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	sequences := map[string]*OpenSequence{}
+	for _, eventType := range params.EventTypes {
+		events, err := s.shipyardControlHandler.GetOpenTriggeredEvents(EventFilter{
+			Project:   params.Project,
+			Stage:     params.Stage,
+			Service:   params.Service,
+			EventType: eventType,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve open %s events: %s", eventType, err.Error())
+		}
+		for _, event := range events {
+			if err := s.addEventToSequences(sequences, event, params.Project); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	result := []*OpenSequence{}
+	for _, sequence := range sequences {
+		if s.matches(sequence, params, selector) {
+			result = append(result, sequence)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Time.Before(result[j].Time)
+	})
+	return result, nil
+}
+
+func (s *SequenceAborter) addEventToSequences(sequences map[string]*OpenSequence, event *models.KeptnContextExtendedCE, project string) error {
+	data := sequenceEventData{}
+	if err := event.DataAs(&data); err != nil {
+		return fmt.Errorf("could not decode event %s: %s", event.ID, err.Error())
+	}
+	if data.Project == "" {
+		data.Project = project
+	}
+
+	// a sequence is aborted per stage, so events of the same context in different stages belong to different sequences
+	key := event.Shkeptncontext + "/" + data.Stage
+	sequence, ok := sequences[key]
+	if !ok {
+		sequence = &OpenSequence{
+			KeptnContext: event.Shkeptncontext,
+			Project:      data.Project,
+			Stage:        data.Stage,
+			Service:      data.Service,
+			Labels:       data.Labels,
+			Time:         event.Time,
+		}
+		sequences[key] = sequence
+	}
+	if event.Time.Before(sequence.Time) {
+		sequence.Time = event.Time
+	}
+	if event.Type != nil {
+		sequence.EventTypes = append(sequence.EventTypes, *event.Type)
+	}
+	return nil
+}
+
+func (s *SequenceAborter) matches(sequence *OpenSequence, params AbortSequencesParams, selector labelSelector) bool {
+	if params.OlderThan > 0 && sequence.Time.After(s.now().Add(-params.OlderThan)) {
+		return false
+	}
+	return selector.Matches(sequence.Labels)
+}
+
+// AbortSequences aborts all open sequences matching the given parameters and returns a result for each of them.
+// In dry-run mode, the matching sequences are reported without being aborted.
+// If the context is cancelled, no further abort requests are sent and the remaining sequences are reported with the context error
+func (s *SequenceAborter) AbortSequences(ctx context.Context, params AbortSequencesParams) ([]*SequenceAbortResult, error) {
+	sequences, err := s.GetOpenSequences(params)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*SequenceAbortResult, len(sequences))
+	for i, sequence := range sequences {
+		results[i] = &SequenceAbortResult{Sequence: *sequence}
+	}
+	if params.DryRun {
+		return results, nil
+	}
+
+	concurrency := params.Concurrency
+	if concurrency == 0 {
+		concurrency = defaultSequenceAbortConcurrency
+	}
+
+	sem := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+	for _, result := range results {
+		if ctx.Err() != nil {
+			result.Error = ctx.Err()
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			result.Error = ctx.Err()
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(result *SequenceAbortResult) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			err := s.sequenceControlHandler.ControlSequence(SequenceControlParams{
+				Project:      result.Sequence.Project,
+				KeptnContext: result.Sequence.KeptnContext,
+				Stage:        result.Sequence.Stage,
+				State:        SequenceControlAbort,
+			})
+			if err != nil {
+				result.Error = err
+				return
+			}
+			result.Aborted = true
+		}(result)
+	}
+	wg.Wait()
+
+	return results, nil
+}
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/go-utils/pkg/common/strutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeShipyardControlHandler struct {
+	events map[string][]*models.KeptnContextExtendedCE
+}
+
+func (f *fakeShipyardControlHandler) GetOpenTriggeredEvents(filter EventFilter) ([]*models.KeptnContextExtendedCE, error) {
+	if filter.EventType == "error" {
+		return nil, errors.New("oops")
+	}
+	return f.events[filter.EventType], nil
+}
+
+type fakeSequenceControlHandler struct {
+	lock     sync.Mutex
+	received []SequenceControlParams
+	failFor  string
+}
+
+func (f *fakeSequenceControlHandler) ControlSequence(params SequenceControlParams) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if params.KeptnContext == f.failFor {
+		return errors.New("could not abort")
+	}
+	f.received = append(f.received, params)
+	return nil
+}
+
+func newOpenTriggeredEvent(keptnContext, eventType, stage string, eventTime time.Time, labels map[string]string) *models.KeptnContextExtendedCE {
+	return &models.KeptnContextExtendedCE{
+		ID:             keptnContext + "-" + eventType,
+		Shkeptncontext: keptnContext,
+		Type:           strutils.Stringp(eventType),
+		Time:           eventTime,
+		Data: map[string]interface{}{
+			"project": "sockshop",
+			"stage":   stage,
+			"service": "carts",
+			"labels":  labels,
+		},
+	}
+}
+
+func newFakeShipyardControlHandler(now time.Time) *fakeShipyardControlHandler {
+	return &fakeShipyardControlHandler{
+		events: map[string][]*models.KeptnContextExtendedCE{
+			"sh.keptn.event.deployment.triggered": {
+				newOpenTriggeredEvent("ctx-1", "sh.keptn.event.deployment.triggered", "dev", now.Add(-2*time.Hour), map[string]string{"rollout": "broken"}),
+				newOpenTriggeredEvent("ctx-2", "sh.keptn.event.deployment.triggered", "dev", now.Add(-10*time.Minute), map[string]string{"rollout": "broken"}),
+			},
+			"sh.keptn.event.test.triggered": {
+				newOpenTriggeredEvent("ctx-1", "sh.keptn.event.test.triggered", "dev", now.Add(-1*time.Hour), map[string]string{"rollout": "broken"}),
+				newOpenTriggeredEvent("ctx-3", "sh.keptn.event.test.triggered", "staging", now.Add(-3*time.Hour), nil),
+			},
+		},
+	}
+}
+
+func TestSequenceAborter_GetOpenSequences(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	eventTypes := []string{"sh.keptn.event.deployment.triggered", "sh.keptn.event.test.triggered"}
+
+	tests := []struct {
+		name         string
+		params       AbortSequencesParams
+		wantContexts []string
+		wantErr      bool
+	}{
+		{
+			name:    "missing project and event types",
+			params:  AbortSequencesParams{},
+			wantErr: true,
+		},
+		{
+			name:    "error while fetching events",
+			params:  AbortSequencesParams{Project: "sockshop", EventTypes: []string{"error"}},
+			wantErr: true,
+		},
+		{
+			name:         "all open sequences ordered by age",
+			params:       AbortSequencesParams{Project: "sockshop", EventTypes: eventTypes},
+			wantContexts: []string{"ctx-3", "ctx-1", "ctx-2"},
+		},
+		{
+			name:         "filter by age",
+			params:       AbortSequencesParams{Project: "sockshop", EventTypes: eventTypes, OlderThan: time.Hour},
+			wantContexts: []string{"ctx-3", "ctx-1"},
+		},
+		{
+			name:         "filter by labels",
+			params:       AbortSequencesParams{Project: "sockshop", EventTypes: eventTypes, LabelSelector: "rollout=broken"},
+			wantContexts: []string{"ctx-1", "ctx-2"},
+		},
+		{
+			name:         "filter by missing label",
+			params:       AbortSequencesParams{Project: "sockshop", EventTypes: eventTypes, LabelSelector: "!rollout"},
+			wantContexts: []string{"ctx-3"},
+		},
+		{
+			name:    "invalid label selector",
+			params:  AbortSequencesParams{Project: "sockshop", EventTypes: eventTypes, LabelSelector: "rollout in (broken"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aborter := NewSequenceAborter(newFakeShipyardControlHandler(now), &fakeSequenceControlHandler{})
+			aborter.now = func() time.Time { return now }
+
+			sequences, err := aborter.GetOpenSequences(tt.params)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			contexts := []string{}
+			for _, sequence := range sequences {
+				contexts = append(contexts, sequence.KeptnContext)
+			}
+			assert.Equal(t, tt.wantContexts, contexts)
+		})
+	}
+}
+
+func TestSequenceAborter_GetOpenSequencesGroupsEventsOfSameSequence(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	aborter := NewSequenceAborter(newFakeShipyardControlHandler(now), &fakeSequenceControlHandler{})
+	aborter.now = func() time.Time { return now }
+
+	sequences, err := aborter.GetOpenSequences(AbortSequencesParams{
+		Project:       "sockshop",
+		Stage:         "dev",
+		EventTypes:    []string{"sh.keptn.event.deployment.triggered", "sh.keptn.event.test.triggered"},
+		LabelSelector: "rollout=broken",
+		OlderThan:     time.Hour,
+	})
+	require.Nil(t, err)
+	require.Len(t, sequences, 1)
+	assert.Equal(t, "ctx-1", sequences[0].KeptnContext)
+	assert.Equal(t, now.Add(-2*time.Hour), sequences[0].Time)
+	assert.Equal(t, []string{"sh.keptn.event.deployment.triggered", "sh.keptn.event.test.triggered"}, sequences[0].EventTypes)
+}
+
+func TestSequenceAborter_AbortSequences(t *testing.T) {
+	now := time.Now()
+	sequenceControl := &fakeSequenceControlHandler{failFor: "ctx-2"}
+	aborter := NewSequenceAborter(newFakeShipyardControlHandler(now), sequenceControl)
+
+	results, err := aborter.AbortSequences(context.Background(), AbortSequencesParams{
+		Project:     "sockshop",
+		EventTypes:  []string{"sh.keptn.event.deployment.triggered", "sh.keptn.event.test.triggered"},
+		Concurrency: 2,
+	})
+	require.Nil(t, err)
+	require.Len(t, results, 3)
+
+	assert.True(t, results[0].Aborted)
+	assert.Nil(t, results[0].Error)
+	assert.True(t, results[1].Aborted)
+	assert.False(t, results[2].Aborted)
+	assert.EqualError(t, results[2].Error, "could not abort")
+
+	require.Len(t, sequenceControl.received, 2)
+	for _, params := range sequenceControl.received {
+		assert.Equal(t, "sockshop", params.Project)
+		assert.Equal(t, SequenceControlAbort, params.State)
+	}
+}
+
+func TestSequenceAborter_AbortSequencesDryRun(t *testing.T) {
+	sequenceControl := &fakeSequenceControlHandler{}
+	aborter := NewSequenceAborter(newFakeShipyardControlHandler(time.Now()), sequenceControl)
+
+	results, err := aborter.AbortSequences(context.Background(), AbortSequencesParams{
+		Project:    "sockshop",
+		EventTypes: []string{"sh.keptn.event.deployment.triggered"},
+		DryRun:     true,
+	})
+	require.Nil(t, err)
+	require.Len(t, results, 2)
+	for _, result := range results {
+		assert.False(t, result.Aborted)
+		assert.Nil(t, result.Error)
+	}
+	assert.Empty(t, sequenceControl.received)
+}
+
+func TestSequenceAborter_AbortSequencesCancelledContext(t *testing.T) {
+	sequenceControl := &fakeSequenceControlHandler{}
+	aborter := NewSequenceAborter(newFakeShipyardControlHandler(time.Now()), sequenceControl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := aborter.AbortSequences(ctx, AbortSequencesParams{
+		Project:     "sockshop",
+		EventTypes:  []string{"sh.keptn.event.deployment.triggered"},
+		Concurrency: 1,
+	})
+	require.Nil(t, err)
+	for _, result := range results {
+		assert.False(t, result.Aborted)
+		assert.Equal(t, context.Canceled, result.Error)
+	}
+	assert.Empty(t, sequenceControl.received)
+}
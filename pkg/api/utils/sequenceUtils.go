@@ -10,6 +10,18 @@ import (
 
 const v1SequenceControlPath = "/v1/sequence/%s/%s/control"
 
+// States that can be set for a sequence via SequenceControlHandler.ControlSequence
+const (
+	SequenceControlAbort  = "abort"
+	SequenceControlPause  = "pause"
+	SequenceControlResume = "resume"
+)
+
+// SequenceControlInterface is the api to control (i.e. pause, resume or abort) sequences
+type SequenceControlInterface interface {
+	ControlSequence(params SequenceControlParams) error
+}
+
 type SequenceControlHandler struct {
 	BaseURL    string
 	AuthToken  string
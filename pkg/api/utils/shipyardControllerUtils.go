@@ -15,6 +15,11 @@ import (
 
 const shipyardControllerBaseURL = "controlPlane"
 
+// ShipyardControlInterface is the api to fetch open triggered events from the shipyard controller
+type ShipyardControlInterface interface {
+	GetOpenTriggeredEvents(filter EventFilter) ([]*models.KeptnContextExtendedCE, error)
+}
+
 // ShipyardControllerHandler handles services
 type ShipyardControllerHandler struct {
 	BaseURL    string
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+)
+
+// RegistrationFilter filters registered integrations. Empty fields match all integrations
+type RegistrationFilter struct {
+	// Name of the integration
+	Name string
+	// Namespace the integration is running in
+	Namespace string
+	// Project of the subscription filter of the integration
+	Project string
+	// Stage of the subscription filter of the integration
+	Stage string
+	// Service of the subscription filter of the integration
+	Service string
+	// Topic is an event type the integration is subscribed to, either explicitly or by a wildcard topic
+	Topic string
+}
+
+func (f RegistrationFilter) query() url.Values {
+	query := url.Values{}
+	for key, value := range map[string]string{
+		"name":      f.Name,
+		"namespace": f.Namespace,
+		"project":   f.Project,
+		"stage":     f.Stage,
+		"service":   f.Service,
+		"topic":     f.Topic,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	return query
+}
+
+func (f RegistrationFilter) apply(integrations []*models.Integration) []*models.Integration {
+	filtered := []*models.Integration{}
+	for _, integration := range integrations {
+		if integration != nil && f.matches(integration) {
+			filtered = append(filtered, integration)
+		}
+	}
+	return filtered
+}
+
+func (f RegistrationFilter) matches(integration *models.Integration) bool {
+	if (f.Name != "" && integration.Name != f.Name) ||
+		(f.Namespace != "" && integration.MetaData.KubernetesMetaData.Namespace != f.Namespace) {
+		return false
+	}
+	if f.Project == "" && f.Stage == "" && f.Service == "" && f.Topic == "" {
+		return true
+	}
+	for _, subscription := range append([]models.Subscription{integration.Subscription}, integration.Subscriptions...) {
+		if f.matchesSubscription(subscription) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f RegistrationFilter) matchesSubscription(subscription models.Subscription) bool {
+	if (f.Project != "" && subscription.Filter.Project != f.Project) ||
+		(f.Stage != "" && subscription.Filter.Stage != f.Stage) ||
+		(f.Service != "" && subscription.Filter.Service != f.Service) {
+		return false
+	}
+	if f.Topic == "" {
+		return true
+	}
+	for _, topic := range subscription.Topics {
+		if MatchSubscriptionTopic(topic, f.Topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchSubscriptionTopic checks whether the event type matches the topic of a subscription, which may contain the
+// wildcards "*" for a single element and ">" for all remaining elements, e.g. "sh.keptn.event.*.triggered"
+func MatchSubscriptionTopic(topic string, eventType string) bool {
+	topicParts := strings.Split(topic, ".")
+	eventTypeParts := strings.Split(eventType, ".")
+	for i, part := range topicParts {
+		if part == ">" {
+			return i == len(topicParts)-1 && len(eventTypeParts) > i
+		}
+		if i >= len(eventTypeParts) || (part != "*" && part != eventTypeParts[i]) {
+			return false
+		}
+	}
+	return len(topicParts) == len(eventTypeParts)
+}
+
+// StaleIntegration is an integration which is presumably not running anymore
+type StaleIntegration struct {
+	Integration *models.Integration
+	// NotSeenFor is the time since the integration has been seen last
+	NotSeenFor time.Duration
+	// Outdated is set if the integration has not been seen within the threshold
+	Outdated bool
+	// DuplicateOf is the ID of the integration with the same name and namespace which has been seen most recently,
+	// if the integration is a duplicate of it
+	DuplicateOf string
+}
+
+// DetectStaleIntegrations returns the integrations which have not been seen within the threshold, as well as those
+// which duplicate an integration with the same name and namespace that has been seen more recently.
+// The stale integrations are ordered by their name, namespace and ID
+func DetectStaleIntegrations(integrations []*models.Integration, threshold time.Duration, now time.Time) []StaleIntegration {
+	type nameAndNamespace struct{ name, namespace string }
+	latest := map[nameAndNamespace]*models.Integration{}
+	for _, integration := range integrations {
+		if integration == nil {
+			continue
+		}
+		key := nameAndNamespace{integration.Name, integration.MetaData.KubernetesMetaData.Namespace}
+		if current, ok := latest[key]; !ok || integration.MetaData.LastSeen.After(current.MetaData.LastSeen) {
+			latest[key] = integration
+		}
+	}
+
+	stale := []StaleIntegration{}
+	for _, integration := range integrations {
+		if integration == nil {
+			continue
+		}
+		s := StaleIntegration{
+			Integration: integration,
+			NotSeenFor:  now.Sub(integration.MetaData.LastSeen),
+		}
+		s.Outdated = s.NotSeenFor > threshold
+		if l := latest[nameAndNamespace{integration.Name, integration.MetaData.KubernetesMetaData.Namespace}]; l != integration {
+			s.DuplicateOf = l.ID
+		}
+		if s.Outdated || s.DuplicateOf != "" {
+			stale = append(stale, s)
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool {
+		a, b := stale[i].Integration, stale[j].Integration
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.MetaData.KubernetesMetaData.Namespace != b.MetaData.KubernetesMetaData.Namespace {
+			return a.MetaData.KubernetesMetaData.Namespace < b.MetaData.KubernetesMetaData.Namespace
+		}
+		return a.ID < b.ID
+	})
+	return stale
+}
+
+// GetStaleIntegrations returns the registered integrations matching the filter which have not been seen within the
+// threshold or which duplicate another integration. See DetectStaleIntegrations
+func (u *UniformHandler) GetStaleIntegrations(filter RegistrationFilter, threshold time.Duration) ([]StaleIntegration, error) {
+	integrations, err := u.GetRegistrationsWithFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	return DetectStaleIntegrations(integrations, threshold, time.Now().UTC()), nil
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestIntegration(id, name, namespace string, lastSeen time.Time, subscription models.Subscription) *models.Integration {
+	return &models.Integration{
+		ID:   id,
+		Name: name,
+		MetaData: models.MetaData{
+			KubernetesMetaData: models.KubernetesMetaData{Namespace: namespace},
+			LastSeen:           lastSeen,
+		},
+		Subscription: subscription,
+	}
+}
+
+func TestUniformHandlerGetRegistrationsWithFilter(t *testing.T) {
+	now := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	integrations := []*models.Integration{
+		newTestIntegration("1", "jmeter-service", "keptn", now, models.Subscription{
+			Topics: []string{"sh.keptn.event.test.triggered"},
+			Filter: models.SubscriptionFilter{Project: "sockshop", Stage: "dev"},
+		}),
+		newTestIntegration("2", "helm-service", "keptn", now, models.Subscription{
+			Topics: []string{"sh.keptn.event.*.triggered"},
+		}),
+		newTestIntegration("3", "helm-service", "keptn-exec", now, models.Subscription{
+			Topics: []string{"sh.keptn.event.deployment.triggered"},
+			Filter: models.SubscriptionFilter{Project: "podtato"},
+		}),
+	}
+
+	var receivedQuery string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedQuery = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(integrations)
+	}))
+	defer ts.Close()
+	handler := NewUniformHandler(ts.URL)
+
+	tests := []struct {
+		name      string
+		filter    RegistrationFilter
+		wantIDs   []string
+		wantQuery string
+	}{
+		{name: "no filter", filter: RegistrationFilter{}, wantIDs: []string{"1", "2", "3"}, wantQuery: ""},
+		{name: "name", filter: RegistrationFilter{Name: "helm-service"}, wantIDs: []string{"2", "3"}, wantQuery: "name=helm-service"},
+		{name: "name and namespace", filter: RegistrationFilter{Name: "helm-service", Namespace: "keptn"}, wantIDs: []string{"2"}, wantQuery: "name=helm-service&namespace=keptn"},
+		{name: "project", filter: RegistrationFilter{Project: "sockshop"}, wantIDs: []string{"1"}, wantQuery: "project=sockshop"},
+		{name: "stage", filter: RegistrationFilter{Stage: "dev"}, wantIDs: []string{"1"}, wantQuery: "stage=dev"},
+		{name: "topic matching wildcard", filter: RegistrationFilter{Topic: "sh.keptn.event.deployment.triggered"}, wantIDs: []string{"2", "3"}, wantQuery: "topic=sh.keptn.event.deployment.triggered"},
+		{name: "no match", filter: RegistrationFilter{Service: "carts"}, wantIDs: []string{}, wantQuery: "service=carts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := handler.GetRegistrationsWithFilter(tt.filter)
+			require.Nil(t, err)
+			ids := []string{}
+			for _, integration := range got {
+				ids = append(ids, integration.ID)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+			assert.Equal(t, tt.wantQuery, receivedQuery)
+		})
+	}
+}
+
+func TestUniformHandlerGetRegistrationsReportsErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"code": 500, "message": "database not available"}`))
+	}))
+	defer ts.Close()
+
+	_, err := NewUniformHandler(ts.URL).GetRegistrations()
+	require.NotNil(t, err)
+	assert.Equal(t, "could not retrieve registrations: database not available", err.Error())
+}
+
+func TestMatchSubscriptionTopic(t *testing.T) {
+	assert.True(t, MatchSubscriptionTopic("sh.keptn.event.test.triggered", "sh.keptn.event.test.triggered"))
+	assert.True(t, MatchSubscriptionTopic("sh.keptn.event.*.triggered", "sh.keptn.event.test.triggered"))
+	assert.True(t, MatchSubscriptionTopic("sh.keptn.>", "sh.keptn.event.dev.delivery.triggered"))
+	assert.False(t, MatchSubscriptionTopic("sh.keptn.event.*.triggered", "sh.keptn.event.dev.delivery.triggered"))
+	assert.False(t, MatchSubscriptionTopic("sh.keptn.event.test.triggered", "sh.keptn.event.test.finished"))
+	assert.False(t, MatchSubscriptionTopic("sh.keptn.event.>", "sh.keptn.event"))
+}
+
+func TestDetectStaleIntegrations(t *testing.T) {
+	now := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	integrations := []*models.Integration{
+		newTestIntegration("a", "jmeter-service", "keptn", now.Add(-10*time.Second), models.Subscription{}),
+		newTestIntegration("b", "helm-service", "keptn", now.Add(-2*time.Hour), models.Subscription{}),
+		newTestIntegration("c", "jmeter-service", "keptn", now.Add(-time.Minute), models.Subscription{}),
+		newTestIntegration("d", "jmeter-service", "keptn-exec", now.Add(-time.Minute), models.Subscription{}),
+		nil,
+	}
+
+	got := DetectStaleIntegrations(integrations, 5*time.Minute, now)
+	require.Len(t, got, 2)
+
+	assert.Equal(t, "b", got[0].Integration.ID)
+	assert.True(t, got[0].Outdated)
+	assert.Equal(t, 2*time.Hour, got[0].NotSeenFor)
+	assert.Equal(t, "", got[0].DuplicateOf)
+
+	assert.Equal(t, "c", got[1].Integration.ID)
+	assert.False(t, got[1].Outdated)
+	assert.Equal(t, "a", got[1].DuplicateOf)
+}
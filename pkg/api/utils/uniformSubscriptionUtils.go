@@ -0,0 +1,237 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+)
+
+const subscriptionTopicPrefix = "sh.keptn."
+
+// ErrSubscriptionNotFound is returned if the subscription or its integration does not exist
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+var errProjectNotFound = errors.New("project not found")
+
+// ValidateSubscriptionTopic checks whether the topic is a valid Keptn event type, i.e. a task event type like
+// "sh.keptn.event.deployment.triggered" or a sequence event type like "sh.keptn.event.dev.delivery.triggered".
+// The topic may contain wildcards: "*" matches a single element of the event type, e.g. "sh.keptn.event.*.triggered",
+// and ">" as last element matches all remaining elements, e.g. "sh.keptn.>".
+// Without wildcards, this is the same check as v0_2_0.IsValidEventType, which can not be used here since the
+// v0_2_0 package depends on this package
+func ValidateSubscriptionTopic(topic string) error {
+	invalid := func(reason string) error {
+		return fmt.Errorf("invalid subscription topic %q: %s", topic, reason)
+	}
+	if !strings.HasPrefix(topic, subscriptionTopicPrefix) {
+		return invalid("must start with " + subscriptionTopicPrefix)
+	}
+	parts := strings.Split(topic, ".")
+	for i, part := range parts {
+		if part == "" {
+			return invalid("must not contain empty elements")
+		}
+		if part == ">" && i != len(parts)-1 {
+			return invalid("'>' is only allowed as last element")
+		}
+		if strings.ContainsAny(part, "*>") && part != "*" && part != ">" {
+			return invalid("wildcards must be separate elements")
+		}
+	}
+	if parts[len(parts)-1] == ">" {
+		if len(parts) > 6 {
+			return invalid("must consist of at most 6 elements")
+		}
+		return nil
+	}
+	if len(parts) != 5 && len(parts) != 6 {
+		return invalid("must be a task event type or a sequence event type")
+	}
+	return nil
+}
+
+// ValidateSubscription checks whether the subscription contains at least one topic and all of its topics are valid
+func ValidateSubscription(subscription models.Subscription) error {
+	if len(subscription.Topics) == 0 {
+		return errors.New("subscription must contain at least one topic")
+	}
+	for _, topic := range subscription.Topics {
+		if err := ValidateSubscriptionTopic(topic); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateSubscription validates the subscription and adds it to the integration with the given ID.
+// The project, stage and service of its filter must exist. The ID of the created subscription is returned
+func (u *UniformHandler) CreateSubscription(integrationID string, subscription models.Subscription) (string, error) {
+	if err := u.validateSubscription(subscription); err != nil {
+		return "", err
+	}
+	body, err := json.Marshal(subscription)
+	if err != nil {
+		return "", err
+	}
+	resp, errResponse := post(u.getSubscriptionsURL(integrationID), body, u)
+	if errResponse != nil {
+		return "", getSubscriptionError(errResponse)
+	}
+	response := &models.CreateSubscriptionResponse{}
+	if err := response.FromJSON([]byte(resp)); err != nil {
+		return "", err
+	}
+	return response.ID, nil
+}
+
+// UpdateSubscription validates the subscription and replaces the subscription with the same ID of the integration with the given ID.
+// The project, stage and service of its filter must exist
+func (u *UniformHandler) UpdateSubscription(integrationID string, subscription models.Subscription) error {
+	if subscription.ID == "" {
+		return errors.New("subscription ID must not be empty")
+	}
+	if err := u.validateSubscription(subscription); err != nil {
+		return err
+	}
+	body, err := json.Marshal(subscription)
+	if err != nil {
+		return err
+	}
+	if _, errResponse := put(u.getSubscriptionsURL(integrationID)+"/"+url.PathEscape(subscription.ID), body, u); errResponse != nil {
+		return getSubscriptionError(errResponse)
+	}
+	return nil
+}
+
+// DeleteSubscription removes the subscription with the given ID from the integration with the given ID
+func (u *UniformHandler) DeleteSubscription(integrationID string, subscriptionID string) error {
+	if subscriptionID == "" {
+		return errors.New("subscription ID must not be empty")
+	}
+	if _, errResponse := deleteRequest(u.getSubscriptionsURL(integrationID)+"/"+url.PathEscape(subscriptionID), u); errResponse != nil {
+		return getSubscriptionError(errResponse)
+	}
+	return nil
+}
+
+// GetSubscription returns the subscription with the given ID of the integration with the given ID.
+// If it does not exist, ErrSubscriptionNotFound is returned
+func (u *UniformHandler) GetSubscription(integrationID string, subscriptionID string) (*models.Subscription, error) {
+	if subscriptionID == "" {
+		return nil, errors.New("subscription ID must not be empty")
+	}
+	subscription := &models.Subscription{}
+	if err := u.getJSON(u.getSubscriptionsURL(integrationID)+"/"+url.PathEscape(subscriptionID), subscription, ErrSubscriptionNotFound); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// GetSubscriptions returns the subscriptions of the integration with the given ID.
+// If the integration does not exist, ErrSubscriptionNotFound is returned
+func (u *UniformHandler) GetSubscriptions(integrationID string) ([]models.Subscription, error) {
+	subscriptions := []models.Subscription{}
+	if err := u.getJSON(u.getSubscriptionsURL(integrationID), &subscriptions, ErrSubscriptionNotFound); err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (u *UniformHandler) getSubscriptionsURL(integrationID string) string {
+	return u.Scheme + "://" + u.getBaseURL() + v1UniformPath + "/" + url.PathEscape(integrationID) + "/subscription"
+}
+
+// validateSubscription validates the topics of the subscription and checks whether the project, stage and service
+// of its filter exist
+func (u *UniformHandler) validateSubscription(subscription models.Subscription) error {
+	if err := ValidateSubscription(subscription); err != nil {
+		return err
+	}
+	filter := subscription.Filter
+	if filter.Project == "" {
+		if filter.Stage != "" || filter.Service != "" {
+			return errors.New("subscription filter must contain a project if it contains a stage or a service")
+		}
+		return nil
+	}
+
+	project := &models.Project{}
+	err := u.getJSON(u.Scheme+"://"+u.getBaseURL()+v1ProjectPath+"/"+url.PathEscape(filter.Project), project, errProjectNotFound)
+	if errors.Is(err, errProjectNotFound) {
+		return fmt.Errorf("project %s of subscription filter does not exist", filter.Project)
+	}
+	if err != nil {
+		return fmt.Errorf("could not validate project %s of subscription filter: %w", filter.Project, err)
+	}
+	if filter.Stage == "" && filter.Service == "" {
+		return nil
+	}
+
+	stageFound := false
+	for _, stage := range project.Stages {
+		if filter.Stage != "" && stage.StageName != filter.Stage {
+			continue
+		}
+		stageFound = true
+		if filter.Service == "" {
+			return nil
+		}
+		for _, service := range stage.Services {
+			if service.ServiceName == filter.Service {
+				return nil
+			}
+		}
+	}
+	if !stageFound {
+		return fmt.Errorf("stage %s of subscription filter does not exist in project %s", filter.Stage, filter.Project)
+	}
+	return fmt.Errorf("service %s of subscription filter does not exist in project %s", filter.Service, filter.Project)
+}
+
+// getJSON retrieves the JSON object with the given URI and decodes it into the result.
+// If the object does not exist, i.e. the response has the status code 404, notFoundErr is returned
+func (u *UniformHandler) getJSON(uri string, result interface{}, notFoundErr error) error {
+	req, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	addAuthHeader(req, u)
+
+	resp, err := u.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return notFoundErr
+	}
+	if resp.StatusCode != http.StatusOK {
+		errObj := &models.Error{}
+		if err := json.Unmarshal(body, errObj); err != nil || errObj.Message == nil {
+			return fmt.Errorf("received unexpected response: %d %s", resp.StatusCode, string(body))
+		}
+		return errors.New(*errObj.Message)
+	}
+	return json.Unmarshal(body, result)
+}
+
+// getSubscriptionError converts the error returned by the shipyard controller, wrapping ErrSubscriptionNotFound if the
+// subscription or its integration does not exist
+func getSubscriptionError(errObj *models.Error) error {
+	if errObj.Code == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrSubscriptionNotFound, errObj.GetMessage())
+	}
+	return errors.New(errObj.GetMessage())
+}
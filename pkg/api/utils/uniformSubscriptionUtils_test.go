@@ -0,0 +1,156 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSubscriptionTopic(t *testing.T) {
+	tests := []struct {
+		topic   string
+		wantErr bool
+	}{
+		{topic: "sh.keptn.event.deployment.triggered"},
+		{topic: "sh.keptn.event.dev.delivery.triggered"},
+		{topic: "sh.keptn.event.*.triggered"},
+		{topic: "sh.keptn.event.dev.*.finished"},
+		{topic: "sh.keptn.>"},
+		{topic: "sh.keptn.event.deployment.>"},
+		{topic: "sh.keptn.event.deployment", wantErr: true},
+		{topic: "sh.keptn.event..triggered", wantErr: true},
+		{topic: "sh.keptn.>.triggered", wantErr: true},
+		{topic: "sh.keptn.event.deploy*.triggered", wantErr: true},
+		{topic: "sh.keptn.event.dev.delivery.triggered.>", wantErr: true},
+		{topic: "my.event.deployment.triggered.x", wantErr: true},
+		{topic: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.topic, func(t *testing.T) {
+			err := ValidateSubscriptionTopic(tt.topic)
+			assert.Equal(t, tt.wantErr, err != nil, "ValidateSubscriptionTopic() error = %v", err)
+		})
+	}
+}
+
+func newTestSubscriptionServer(t *testing.T, received *[]models.Subscription) *httptest.Server {
+	project := models.Project{
+		ProjectName: "sockshop",
+		Stages: []*models.Stage{
+			{StageName: "dev", Services: []*models.Service{{ServiceName: "carts"}}},
+			{StageName: "production", Services: []*models.Service{{ServiceName: "carts"}, {ServiceName: "orders"}}},
+		},
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/v1/project/sockshop":
+			json.NewEncoder(w).Encode(project)
+		case r.URL.Path == "/v1/project/unknown":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code": 404, "message": "project not found"}`))
+		case r.URL.Path == "/v1/project/deleted":
+			w.WriteHeader(http.StatusNotFound)
+		case r.URL.Path == "/v1/project/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		case r.URL.Path == "/v1/uniform/registration/my-integration/subscription" && r.Method == http.MethodPost:
+			body, _ := ioutil.ReadAll(r.Body)
+			subscription := models.Subscription{}
+			require.Nil(t, json.Unmarshal(body, &subscription))
+			*received = append(*received, subscription)
+			w.Write([]byte(`{"id": "my-subscription"}`))
+		case r.URL.Path == "/v1/uniform/registration/my-integration/subscription" && r.Method == http.MethodGet:
+			json.NewEncoder(w).Encode(*received)
+		case r.URL.Path == "/v1/uniform/registration/my-integration/subscription/my-subscription":
+			switch r.Method {
+			case http.MethodGet:
+				json.NewEncoder(w).Encode((*received)[0])
+			case http.MethodPut:
+				body, _ := ioutil.ReadAll(r.Body)
+				subscription := models.Subscription{}
+				require.Nil(t, json.Unmarshal(body, &subscription))
+				(*received)[0] = subscription
+			case http.MethodDelete:
+				*received = nil
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code": 404, "message": "not found"}`))
+		}
+	}))
+}
+
+func TestUniformHandlerSubscriptions(t *testing.T) {
+	received := []models.Subscription{}
+	ts := newTestSubscriptionServer(t, &received)
+	defer ts.Close()
+	handler := NewUniformHandler(ts.URL)
+
+	subscription := models.Subscription{
+		Topics: []string{"sh.keptn.event.deployment.triggered"},
+		Filter: models.SubscriptionFilter{Project: "sockshop", Stage: "dev", Service: "carts"},
+	}
+	id, err := handler.CreateSubscription("my-integration", subscription)
+	require.Nil(t, err)
+	assert.Equal(t, "my-subscription", id)
+
+	got, err := handler.GetSubscription("my-integration", id)
+	require.Nil(t, err)
+	assert.Equal(t, subscription, *got)
+
+	subscription.ID = id
+	subscription.Filter.Stage = ""
+	subscription.Filter.Service = "orders"
+	require.Nil(t, handler.UpdateSubscription("my-integration", subscription))
+
+	subscriptions, err := handler.GetSubscriptions("my-integration")
+	require.Nil(t, err)
+	assert.Equal(t, []models.Subscription{subscription}, subscriptions)
+
+	require.Nil(t, handler.DeleteSubscription("my-integration", id))
+
+	err = handler.DeleteSubscription("unknown-integration", id)
+	assert.True(t, errors.Is(err, ErrSubscriptionNotFound))
+	_, err = handler.GetSubscriptions("unknown-integration")
+	assert.Equal(t, ErrSubscriptionNotFound, err)
+}
+
+func TestUniformHandlerSubscriptionValidation(t *testing.T) {
+	received := []models.Subscription{}
+	ts := newTestSubscriptionServer(t, &received)
+	defer ts.Close()
+	handler := NewUniformHandler(ts.URL)
+
+	tests := []struct {
+		name    string
+		topics  []string
+		filter  models.SubscriptionFilter
+		wantErr string
+	}{
+		{name: "no topics", filter: models.SubscriptionFilter{Project: "sockshop"}},
+		{name: "invalid topic", topics: []string{"sh.keptn.event.deployment"}},
+		{name: "unknown project", topics: []string{"sh.keptn.>"}, filter: models.SubscriptionFilter{Project: "unknown"}, wantErr: "project unknown of subscription filter does not exist"},
+		{name: "unknown project without error body", topics: []string{"sh.keptn.>"}, filter: models.SubscriptionFilter{Project: "deleted"}, wantErr: "project deleted of subscription filter does not exist"},
+		{name: "project not retrievable", topics: []string{"sh.keptn.>"}, filter: models.SubscriptionFilter{Project: "broken"}, wantErr: "could not validate project broken"},
+		{name: "unknown stage", topics: []string{"sh.keptn.>"}, filter: models.SubscriptionFilter{Project: "sockshop", Stage: "hardening"}},
+		{name: "unknown service", topics: []string{"sh.keptn.>"}, filter: models.SubscriptionFilter{Project: "sockshop", Stage: "dev", Service: "orders"}},
+		{name: "stage without project", topics: []string{"sh.keptn.>"}, filter: models.SubscriptionFilter{Stage: "dev"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := handler.CreateSubscription("my-integration", models.Subscription{Topics: tt.topics, Filter: tt.filter})
+			require.NotNil(t, err)
+			if tt.wantErr != "" {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+	assert.Empty(t, received)
+}
@@ -90,18 +90,25 @@ func (u *UniformHandler) RegisterIntegration(integration models.Integration) (st
 }
 
 func (u *UniformHandler) UnregisterIntegration(integrationID string) error {
-	_, err := delete(u.Scheme+"://"+u.getBaseURL()+v1UniformPath+"/"+integrationID, u)
+	_, err := deleteRequest(u.Scheme+"://"+u.getBaseURL()+v1UniformPath+"/"+integrationID, u)
 	if err != nil {
 		return fmt.Errorf(err.GetMessage())
 	}
 	return nil
 }
 
+// GetRegistrations returns all registered integrations
 func (u *UniformHandler) GetRegistrations() ([]*models.Integration, error) {
+	return u.GetRegistrationsWithFilter(RegistrationFilter{})
+}
+
+// GetRegistrationsWithFilter returns the registered integrations matching the filter
+func (u *UniformHandler) GetRegistrationsWithFilter(filter RegistrationFilter) ([]*models.Integration, error) {
 	url, err := url.Parse(u.Scheme + "://" + u.getBaseURL() + v1UniformPath)
 	if err != nil {
 		return nil, err
 	}
+	url.RawQuery = filter.query().Encode()
 
 	req, err := http.NewRequest("GET", url.String(), nil)
 	if err != nil {
@@ -121,14 +128,18 @@ func (u *UniformHandler) GetRegistrations() ([]*models.Integration, error) {
 		return nil, err
 	}
 
-	if resp.StatusCode == http.StatusOK {
-		var received []*models.Integration
-		err := json.Unmarshal(body, &received)
-		if err != nil {
-			return nil, err
+	if resp.StatusCode != http.StatusOK {
+		respErr := models.Error{}
+		if err := json.Unmarshal(body, &respErr); err != nil || respErr.Message == nil {
+			return nil, fmt.Errorf("could not retrieve registrations: received unexpected response: %d %s", resp.StatusCode, string(body))
 		}
-		return received, nil
+		return nil, fmt.Errorf("could not retrieve registrations: %s", respErr.GetMessage())
 	}
 
-	return nil, nil
+	var received []*models.Integration
+	if err := json.Unmarshal(body, &received); err != nil {
+		return nil, err
+	}
+	// the filter is also applied locally, since older versions of the shipyard controller ignore it
+	return filter.apply(received), nil
 }
@@ -1,7 +1,10 @@
 package keptn
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"strings"
 )
@@ -32,3 +35,22 @@ func ExecuteCommandInDirectory(command string, args []string, directory string)
 	}
 	return string(out), nil
 }
+
+// ExecuteCommandOutputInDirectory executes the command using the args within the specified directory and returns
+// its standard output only. env is appended to the env of the current process and stdin is passed to the command if set.
+// The standard error of the command is only used for the error message if the command fails
+func ExecuteCommandOutputInDirectory(command string, args []string, directory string, env []string, stdin io.Reader) (string, error) {
+	cmd := exec.Command(command, args...)
+	cmd.Dir = directory
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	cmd.Stdin = stdin
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("Error executing command %s %s: %w: %s", command, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
@@ -0,0 +1,276 @@
+package keptn
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	api "github.com/keptn/go-utils/pkg/api/utils"
+	keptnlib "github.com/keptn/go-utils/pkg/lib"
+)
+
+// DefaultGitProjectBranch is the branch containing the project level resources by default
+const DefaultGitProjectBranch = "master"
+
+const defaultGitCommitAuthor = "keptn"
+const defaultGitCommitEmail = "keptn@keptn.sh"
+
+// GitResourceProvider is an api.ResourceProvider for local clones of the git repositories of projects, which are
+// structured like the configuration service stores the resources:
+//
+//	<root>/<project> is the working copy of the repository of the project
+//	the project branch (master by default) contains the project level resources
+//	the branch <stage> contains the stage level resources
+//	the directory <service> of the branch <stage> contains the service level resources
+//
+// Resources are read from and written to the branches using the git binary, independently of the branch checked out
+// in the working copy. Branches which only exist in the remote "origin" are considered as well.
+// The resources of a stage also include the resources of its services, since these are stored in the same branch
+type GitResourceProvider struct {
+	rootDir       string
+	projectBranch string
+	author        string
+	email         string
+}
+
+// GitResourceProviderOption can be used to configure a GitResourceProvider
+type GitResourceProviderOption func(*GitResourceProvider)
+
+// WithGitProjectBranch sets the branch containing the project level resources
+func WithGitProjectBranch(branch string) GitResourceProviderOption {
+	return func(g *GitResourceProvider) {
+		g.projectBranch = branch
+	}
+}
+
+// WithGitCommitAuthor sets the author of the commits created by PutResource
+func WithGitCommitAuthor(name string, email string) GitResourceProviderOption {
+	return func(g *GitResourceProvider) {
+		g.author = name
+		g.email = email
+	}
+}
+
+// NewGitResourceProvider creates a new GitResourceProvider for the working copies in the given root directory
+func NewGitResourceProvider(rootDir string, opts ...GitResourceProviderOption) *GitResourceProvider {
+	g := &GitResourceProvider{
+		rootDir:       rootDir,
+		projectBranch: DefaultGitProjectBranch,
+		author:        defaultGitCommitAuthor,
+		email:         defaultGitCommitEmail,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// GetResource reads the resource with the given URI from the branch of the scope or returns
+// api.ResourceNotFoundError if it does not exist. The version of the resource is the commit ID of the branch
+func (g *GitResourceProvider) GetResource(scope api.ResourceScope, resourceURI string) (*models.Resource, error) {
+	repoDir, err := g.getRepositoryDir(scope)
+	if err != nil {
+		return nil, err
+	}
+	filePath, err := g.getFilePath(scope, resourceURI)
+	if err != nil {
+		return nil, err
+	}
+	commitID, err := g.resolveBranch(repoDir, g.getBranch(scope))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := runGitCommand(repoDir, []string{"cat-file", "-e", commitID + ":" + filePath}); err != nil {
+		return nil, api.ResourceNotFoundError
+	}
+	content, err := runGitCommand(repoDir, []string{"cat-file", "blob", commitID + ":" + filePath})
+	if err != nil {
+		return nil, err
+	}
+	uri := resourceURI
+	return &models.Resource{
+		ResourceURI:     &uri,
+		ResourceContent: content,
+		Metadata:        &models.Version{Version: commitID},
+	}, nil
+}
+
+// GetAllResources lists the resources of the scope, ordered by their resource URI. The content of the resources is not set
+func (g *GitResourceProvider) GetAllResources(scope api.ResourceScope) ([]*models.Resource, error) {
+	repoDir, err := g.getRepositoryDir(scope)
+	if err != nil {
+		return nil, err
+	}
+	commitID, err := g.resolveBranch(repoDir, g.getBranch(scope))
+	if err == api.ResourceNotFoundError {
+		return []*models.Resource{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"ls-tree", "-r", "-z", "--name-only", commitID}
+	if scope.IsServiceLevel() {
+		args = append(args, "--", scope.Service+"/")
+	}
+	out, err := runGitCommand(repoDir, args)
+	if err != nil {
+		return nil, err
+	}
+	resources := []*models.Resource{}
+	for _, file := range strings.Split(out, "\x00") {
+		if file == "" {
+			continue
+		}
+		uri := file
+		if scope.IsServiceLevel() {
+			uri = strings.TrimPrefix(file, scope.Service+"/")
+		}
+		resources = append(resources, &models.Resource{ResourceURI: &uri, Metadata: &models.Version{Version: commitID}})
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return *resources[i].ResourceURI < *resources[j].ResourceURI
+	})
+	return resources, nil
+}
+
+// PutResource commits the resource to the branch of the scope and returns the ID of the resulting commit.
+// If the content of the resource has not changed, no commit is created and the current commit ID of the branch is returned.
+// The commit is created with the git plumbing commands, so the checked out branch, the index and the files of the
+// working copy are not modified. Hence, if the branch of the scope is checked out, the files of the working copy do not
+// reflect the new commit until they are updated, e.g. with git reset
+func (g *GitResourceProvider) PutResource(scope api.ResourceScope, resource *models.Resource) (string, error) {
+	if resource == nil || resource.ResourceURI == nil {
+		return "", &api.InvalidResourceURIError{Rule: api.ResourceURIRuleNotEmpty}
+	}
+	repoDir, err := g.getRepositoryDir(scope)
+	if err != nil {
+		return "", err
+	}
+	filePath, err := g.getFilePath(scope, *resource.ResourceURI)
+	if err != nil {
+		return "", err
+	}
+	branch := g.getBranch(scope)
+	commitID, err := g.resolveBranch(repoDir, branch)
+	if err != nil {
+		return "", fmt.Errorf("could not resolve branch %s: %w", branch, err)
+	}
+
+	out, err := runGitCommandWithEnv(repoDir, []string{"hash-object", "-w", "--stdin"}, nil, strings.NewReader(resource.ResourceContent))
+	if err != nil {
+		return "", err
+	}
+	blobID := strings.TrimSpace(out)
+
+	// only commit if the content of the resource has actually changed
+	if out, err := runGitCommand(repoDir, []string{"rev-parse", "--verify", "--quiet", commitID + ":" + filePath}); err == nil && strings.TrimSpace(out) == blobID {
+		return commitID, nil
+	}
+
+	// build the tree of the new commit in a temporary index, so that the index of the working copy is not modified
+	indexDir, err := ioutil.TempDir("", "keptn-git-index")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(indexDir)
+	env := []string{
+		"GIT_INDEX_FILE=" + filepath.Join(indexDir, "index"),
+		"GIT_AUTHOR_NAME=" + g.author,
+		"GIT_AUTHOR_EMAIL=" + g.email,
+		"GIT_COMMITTER_NAME=" + g.author,
+		"GIT_COMMITTER_EMAIL=" + g.email,
+	}
+	if _, err := runGitCommandWithEnv(repoDir, []string{"read-tree", commitID}, env, nil); err != nil {
+		return "", err
+	}
+	if _, err := runGitCommandWithEnv(repoDir, []string{"update-index", "--add", "--cacheinfo", "100644," + blobID + "," + filePath}, env, nil); err != nil {
+		return "", err
+	}
+	out, err = runGitCommandWithEnv(repoDir, []string{"write-tree"}, env, nil)
+	if err != nil {
+		return "", err
+	}
+	out, err = runGitCommandWithEnv(repoDir, []string{"commit-tree", strings.TrimSpace(out), "-p", commitID, "-m", "Update resource " + filePath}, env, nil)
+	if err != nil {
+		return "", err
+	}
+	newCommitID := strings.TrimSpace(out)
+
+	// the branch is only updated if it has not been changed in the meantime. If the branch only exists in the remote
+	// "origin" so far, the local branch is created
+	oldValue := commitID
+	if _, err := runGitCommand(repoDir, []string{"rev-parse", "--verify", "--quiet", "refs/heads/" + branch}); err != nil {
+		oldValue = ""
+	}
+	if _, err := runGitCommand(repoDir, []string{"update-ref", "-m", "Update resource " + filePath, "refs/heads/" + branch, newCommitID, oldValue}); err != nil {
+		return "", err
+	}
+	return newCommitID, nil
+}
+
+func (g *GitResourceProvider) getRepositoryDir(scope api.ResourceScope) (string, error) {
+	if err := scope.Validate(); err != nil {
+		return "", err
+	}
+	for _, name := range []string{scope.Project, scope.Stage, scope.Service} {
+		if name != "" && !ValidateKeptnEntityName(name) {
+			return "", errors.New("invalid resource scope: " + scope.String())
+		}
+	}
+	return filepath.Join(g.rootDir, scope.Project), nil
+}
+
+func (g *GitResourceProvider) getBranch(scope api.ResourceScope) string {
+	if scope.Stage == "" {
+		return g.projectBranch
+	}
+	return scope.Stage
+}
+
+// getFilePath returns the slash-separated path of the resource within the branch of the scope
+func (g *GitResourceProvider) getFilePath(scope api.ResourceScope, resourceURI string) (string, error) {
+	normalizedURI, err := api.NormalizeResourceURI(resourceURI)
+	if err != nil {
+		return "", err
+	}
+	if scope.IsServiceLevel() {
+		return scope.Service + "/" + normalizedURI, nil
+	}
+	return normalizedURI, nil
+}
+
+// resolveBranch returns the commit ID of the local branch or, if it does not exist locally, of the branch of the remote
+// "origin". If neither exists, api.ResourceNotFoundError is returned
+func (g *GitResourceProvider) resolveBranch(repoDir string, branch string) (string, error) {
+	if _, err := os.Stat(repoDir); errors.Is(err, os.ErrNotExist) {
+		return "", api.ResourceNotFoundError
+	}
+	for _, ref := range []string{"refs/heads/" + branch, "refs/remotes/origin/" + branch} {
+		out, err := runGitCommand(repoDir, []string{"rev-parse", "--verify", "--quiet", ref + "^{commit}"})
+		if err == nil {
+			return strings.TrimSpace(out), nil
+		}
+	}
+	if _, err := runGitCommand(repoDir, []string{"rev-parse", "--git-dir"}); err != nil {
+		return "", fmt.Errorf("%s is not a git repository: %w", repoDir, err)
+	}
+	return "", api.ResourceNotFoundError
+}
+
+// runGitCommand runs git with the given arguments in the repository and returns its standard output.
+// The standard error of git, e.g. warnings, is only used for the error message if the command fails
+func runGitCommand(repoDir string, args []string) (string, error) {
+	return runGitCommandWithEnv(repoDir, args, nil, nil)
+}
+
+// runGitCommandWithEnv runs git like runGitCommand, appending env to the environment of the current process
+// and passing stdin to git if it is set
+func runGitCommandWithEnv(repoDir string, args []string, env []string, stdin io.Reader) (string, error) {
+	return keptnlib.ExecuteCommandOutputInDirectory("git", args, repoDir, env, stdin)
+}
@@ -0,0 +1,210 @@
+package keptn
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	api "github.com/keptn/go-utils/pkg/api/utils"
+)
+
+// runGit runs git with a fixed identity in the given directory and fails the test on errors
+func runGit(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@keptn.sh"}, args...)...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+// newGitFixture creates the repository of the project sockshop with the stage branch dev in a temporary directory
+// and returns the root directory of the repository
+func newGitFixture(t *testing.T) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	rootDir := t.TempDir()
+	repoDir := filepath.Join(rootDir, "sockshop")
+	if err := os.Mkdir(repoDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	runGit(t, repoDir, "init")
+	runGit(t, repoDir, "symbolic-ref", "HEAD", "refs/heads/master")
+	writeLocalResources(t, repoDir, map[string]string{
+		"shipyard.yaml": "project-shipyard",
+		"slo.yaml":      "project-slo",
+	})
+	runGit(t, repoDir, "add", ".")
+	runGit(t, repoDir, "commit", "-m", "init project")
+	runGit(t, repoDir, "checkout", "-b", "dev")
+	writeLocalResources(t, repoDir, map[string]string{
+		"slo.yaml":               "stage-slo",
+		"carts/slo.yaml":         "service-slo",
+		"carts/helm/values.yaml": "service-values",
+	})
+	runGit(t, repoDir, "add", ".")
+	runGit(t, repoDir, "commit", "-m", "add stage dev")
+	runGit(t, repoDir, "checkout", "master")
+	return rootDir
+}
+
+func TestGitResourceProvider(t *testing.T) {
+	rootDir := newGitFixture(t)
+
+	k := &KeptnBase{
+		Event:            &testEventProperties{project: "sockshop", stage: "dev", service: "carts"},
+		ResourceProvider: NewGitResourceProvider(rootDir),
+	}
+
+	tests := []struct {
+		resource string
+		want     string
+		wantErr  bool
+	}{
+		{resource: "slo.yaml", want: "service-slo"},
+		{resource: "helm/values.yaml", want: "service-values"},
+		{resource: "shipyard.yaml", want: "project-shipyard"},
+		{resource: "remediation.yaml", wantErr: true},
+		{resource: "../slo.yaml", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := k.GetKeptnResource(tt.resource)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("GetKeptnResource(%q) error = %v, wantErr %v", tt.resource, err, tt.wantErr)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("GetKeptnResource(%q) got = %s, want %s", tt.resource, got, tt.want)
+		}
+	}
+
+	resources, err := k.GetResourceProvider().GetAllResources(api.ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"})
+	if err != nil {
+		t.Fatalf("GetAllResources() error = %v", err)
+	}
+	uris := []string{}
+	for _, resource := range resources {
+		uris = append(uris, *resource.ResourceURI)
+	}
+	if want := []string{"helm/values.yaml", "slo.yaml"}; !reflect.DeepEqual(uris, want) {
+		t.Errorf("GetAllResources() got = %v, want %v", uris, want)
+	}
+
+	resources, err = k.GetResourceProvider().GetAllResources(api.ResourceScope{Project: "sockshop", Stage: "production"})
+	if err != nil || len(resources) != 0 {
+		t.Errorf("GetAllResources() of missing stage got = %v, %v, want empty list", resources, err)
+	}
+}
+
+func TestGitResourceProviderPutResource(t *testing.T) {
+	rootDir := newGitFixture(t)
+
+	provider := NewGitResourceProvider(rootDir)
+	scope := api.ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"}
+	uri := "slo.yaml"
+
+	version, err := provider.PutResource(scope, &models.Resource{ResourceURI: &uri, ResourceContent: "updated-slo"})
+	if err != nil {
+		t.Fatalf("PutResource() error = %v", err)
+	}
+	resource, err := provider.GetResource(scope, uri)
+	if err != nil {
+		t.Fatalf("GetResource() error = %v", err)
+	}
+	if resource.ResourceContent != "updated-slo" || resource.Metadata.Version != version {
+		t.Errorf("GetResource() got = %s in version %s, want updated-slo in version %s", resource.ResourceContent, resource.Metadata.Version, version)
+	}
+
+	unchangedVersion, err := provider.PutResource(scope, &models.Resource{ResourceURI: &uri, ResourceContent: "updated-slo"})
+	if err != nil || unchangedVersion != version {
+		t.Errorf("PutResource() of unchanged content got = %s, %v, want %s", unchangedVersion, err, version)
+	}
+
+	// the stage level is not affected by the update of the service level
+	resource, err = provider.GetResource(api.ResourceScope{Project: "sockshop", Stage: "dev"}, uri)
+	if err != nil || resource.ResourceContent != "stage-slo" {
+		t.Errorf("GetResource() of stage got = %v, %v, want stage-slo", resource, err)
+	}
+
+	// the working copy is not modified
+	repoDir := filepath.Join(rootDir, "sockshop")
+	if head, err := runGitCommand(repoDir, []string{"symbolic-ref", "HEAD"}); err != nil || strings.TrimSpace(head) != "refs/heads/master" {
+		t.Errorf("checked out branch got = %s, %v, want refs/heads/master", head, err)
+	}
+	if status, err := runGitCommand(repoDir, []string{"status", "--porcelain"}); err != nil || status != "" {
+		t.Errorf("status of working copy got = %q, %v, want no changes", status, err)
+	}
+	if content, err := ioutil.ReadFile(filepath.Join(repoDir, "slo.yaml")); err != nil || string(content) != "project-slo" {
+		t.Errorf("slo.yaml of working copy got = %s, %v, want project-slo", content, err)
+	}
+}
+
+func TestGitResourceProviderPutResourceToRemoteBranch(t *testing.T) {
+	rootDir := newGitFixture(t)
+
+	cloneDir := t.TempDir()
+	runGit(t, cloneDir, "clone", filepath.Join(rootDir, "sockshop"), "sockshop")
+
+	provider := NewGitResourceProvider(cloneDir)
+	scope := api.ResourceScope{Project: "sockshop", Stage: "dev"}
+	uri := "remediation.yaml"
+	version, err := provider.PutResource(scope, &models.Resource{ResourceURI: &uri, ResourceContent: "stage-remediation"})
+	if err != nil {
+		t.Fatalf("PutResource() error = %v", err)
+	}
+
+	// the local branch is created on top of the remote branch
+	out, err := runGitCommand(filepath.Join(cloneDir, "sockshop"), []string{"rev-parse", "refs/heads/dev^", "refs/remotes/origin/dev"})
+	if parents := strings.Fields(out); err != nil || len(parents) != 2 || parents[0] != parents[1] {
+		t.Errorf("parent of local branch got = %v, %v, want the commit of origin/dev", parents, err)
+	}
+	resource, err := provider.GetResource(api.ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"}, "slo.yaml")
+	if err != nil || resource.ResourceContent != "service-slo" || resource.Metadata.Version != version {
+		t.Errorf("GetResource() got = %v, %v, want service-slo in version %s", resource, err, version)
+	}
+
+	_, err = provider.PutResource(api.ResourceScope{Project: "sockshop", Stage: "production"}, &models.Resource{ResourceURI: &uri})
+	if !errors.Is(err, api.ResourceNotFoundError) {
+		t.Errorf("PutResource() to missing branch error = %v, want ResourceNotFoundError", err)
+	}
+}
+
+func TestGitResourceProviderReadsRemoteBranches(t *testing.T) {
+	rootDir := newGitFixture(t)
+
+	cloneDir := t.TempDir()
+	runGit(t, cloneDir, "clone", filepath.Join(rootDir, "sockshop"), "sockshop")
+
+	provider := NewGitResourceProvider(cloneDir)
+	resource, err := provider.GetResource(api.ResourceScope{Project: "sockshop", Stage: "dev", Service: "carts"}, "slo.yaml")
+	if err != nil || resource.ResourceContent != "service-slo" {
+		t.Errorf("GetResource() got = %v, %v, want service-slo", resource, err)
+	}
+}
+
+func TestRunGitCommandSeparatesStderr(t *testing.T) {
+	rootDir := newGitFixture(t)
+	repoDir := filepath.Join(rootDir, "sockshop")
+
+	// git warns about the ambiguous ref name on stderr, which must not be part of the output
+	runGit(t, repoDir, "tag", "master", "dev")
+	out, err := runGitCommand(repoDir, []string{"rev-parse", "master"})
+	if err != nil {
+		t.Fatalf("runGitCommand() error = %v", err)
+	}
+	if len(strings.TrimSpace(out)) != 40 {
+		t.Errorf("runGitCommand() got = %q, want commit ID", out)
+	}
+
+	_, err = runGitCommand(repoDir, []string{"cat-file", "blob", "master:missing.yaml"})
+	if err == nil || !strings.Contains(err.Error(), "missing.yaml") {
+		t.Errorf("runGitCommand() error = %v, want error containing the stderr of git", err)
+	}
+}
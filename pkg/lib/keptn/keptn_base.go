@@ -2,6 +2,7 @@ package keptn
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"log"
@@ -18,7 +19,12 @@ import (
 )
 
 type KeptnOpts struct {
-	UseLocalFileSystem      bool
+	UseLocalFileSystem bool
+	// LocalResourceDirectory is the directory resources are read from if UseLocalFileSystem is set.
+	// Defaults to DefaultLocalResourceDirectory. See api.LocalResourceReader for the expected layout of the directory
+	LocalResourceDirectory string
+	// ResourceProvider is used to retrieve and store resources instead of the configuration service, if set
+	ResourceProvider        api.ResourceProvider
 	ConfigurationServiceURL string
 	EventBrokerURL          string // Deprecated: use EventSender instead
 	DatastoreURL            string
@@ -45,8 +51,13 @@ type KeptnBase struct {
 
 	EventBrokerURL     string // Deprecated: use EventSender instead
 	UseLocalFileSystem bool
-	ResourceHandler    *api.ResourceHandler
-	EventHandler       *api.EventHandler
+	// ResourceHandler is the client of the configuration service.
+	// Deprecated: use ResourceProvider instead, the ResourceHandler is only used for resources if no ResourceProvider is set
+	ResourceHandler *api.ResourceHandler
+	EventHandler    *api.EventHandler
+	// ResourceProvider is used to retrieve and store resources, e.g. an api.ResourceHandler for the configuration
+	// service, an api.LocalResourceReader in the local file system mode or a GitResourceProvider
+	ResourceProvider api.ResourceProvider
 }
 
 type EventProperties interface {
@@ -78,54 +89,62 @@ const ConfigurationServiceURL = "configuration-service:8080"
 const DatastoreURL = "mongodb-datastore:8080"
 const DefaultLoggingServiceName = "keptn"
 
+// DefaultLocalResourceDirectory is the directory resources are read from in the local file system mode by default
+const DefaultLocalResourceDirectory = "."
+
+// ErrNoResourceProvider is returned if a resource is requested from a KeptnBase without a ResourceProvider
+var ErrNoResourceProvider = errors.New("no resource provider configured")
+
+// GetResourceProvider returns the provider used to retrieve and store resources, i.e. the ResourceProvider if it is set.
+// Otherwise, a provider for the DefaultLocalResourceDirectory is returned if UseLocalFileSystem is set, and the
+// deprecated ResourceHandler if it is set. If none of them is available, nil is returned
+func (k *KeptnBase) GetResourceProvider() api.ResourceProvider {
+	if k.ResourceProvider != nil {
+		return k.ResourceProvider
+	}
+	if k.UseLocalFileSystem {
+		return api.NewLocalResourceReader(DefaultLocalResourceDirectory)
+	}
+	if k.ResourceHandler != nil {
+		return k.ResourceHandler
+	}
+	return nil
+}
+
+func (k *KeptnBase) getResourceResolver() (*api.ResourceResolver, error) {
+	provider := k.GetResourceProvider()
+	if provider == nil {
+		return nil, ErrNoResourceProvider
+	}
+	return api.NewResourceResolver(provider), nil
+}
+
 // GetSLIConfiguration retrieves the SLI configuration for a service considering SLI configuration on stage and project level.
 // First, the configuration of project-level is retrieved, which is then overridden by configuration on stage level,
 // overridden by configuration on service level.
 func (k *KeptnBase) GetSLIConfiguration(project string, stage string, service string, resourceURI string) (map[string]string, error) {
-	var res *models.Resource
-	var err error
 	SLIs := make(map[string]string)
-
-	// get sli config from project
-	if project != "" {
-		res, err = k.ResourceHandler.GetProjectResource(project, resourceURI)
-		if err != nil {
-			// return error except "resource not found" type
-			if !strings.Contains(strings.ToLower(err.Error()), "resource not found") {
-				return nil, err
-			}
-		}
-		SLIs, err = addResourceContentToSLIMap(SLIs, res)
-		if err != nil {
-			return nil, err
-		}
+	if project == "" {
+		return SLIs, nil
 	}
 
-	// get sli config from stage
-	if project != "" && stage != "" {
-		res, err = k.ResourceHandler.GetStageResource(project, stage, resourceURI)
-		if err != nil {
-			// return error except "resource not found" type
-			if !strings.Contains(strings.ToLower(err.Error()), "resource not found") {
-				return nil, err
-			}
-		}
-		SLIs, err = addResourceContentToSLIMap(SLIs, res)
-		if err != nil {
-			return nil, err
-		}
+	scope := api.ResourceScope{Project: project}
+	if stage != "" {
+		scope.Stage = stage
+		scope.Service = service
+	}
+	resolver, err := k.getResourceResolver()
+	if err != nil {
+		return nil, err
+	}
+	resources, err := resolver.GetResourceFromAllLevels(scope, resourceURI)
+	if err != nil {
+		return nil, err
 	}
 
-	// get sli config from service
-	if project != "" && stage != "" && service != "" {
-		res, err = k.ResourceHandler.GetServiceResource(project, stage, service, resourceURI)
-		if err != nil {
-			// return error except "resource not found" type
-			if !strings.Contains(strings.ToLower(err.Error()), "resource not found") {
-				return nil, err
-			}
-		}
-		SLIs, err = addResourceContentToSLIMap(SLIs, res)
+	// resources are ordered from project to service level, hence more specific configuration overrides the previous one
+	for _, res := range resources {
+		SLIs, err = addResourceContentToSLIMap(SLIs, res.Resource)
 		if err != nil {
 			return nil, err
 		}
@@ -149,34 +168,30 @@ func addResourceContentToSLIMap(SLIs map[string]string, resource *models.Resourc
 	return SLIs, nil
 }
 
-// GetKeptnResource returns a resource from the configuration repo based on the incoming cloud events project, service and stage
+// GetKeptnResource returns a resource from the configuration repo based on the incoming cloud events project, service and stage.
+// If the resource is not available for the service, it is retrieved from the stage or, as a last resort, from the project.
+// Events without a stage are served from the project only, even if they contain a service
 func (k *KeptnBase) GetKeptnResource(resource string) ([]byte, error) {
 
-	// if we run in a runlocal mode we are just getting the file from the local disk
-	if k.UseLocalFileSystem {
-		return getKeptnResourceFromLocal(resource)
+	// get it from the most specific level it is available on, i.e. service, stage or project.
+	// The same lookup is used for all resource providers, e.g. the local resource directory in the local file system mode
+	resolver, err := k.getResourceResolver()
+	if err != nil {
+		return nil, err
 	}
-
-	// get it from KeptnBase
-	requestedResource, err := k.ResourceHandler.GetServiceResource(k.Event.GetProject(), k.Event.GetStage(), k.Event.GetService(), resource)
+	scope := api.ResourceScope{Project: k.Event.GetProject(), Stage: k.Event.GetStage(), Service: k.Event.GetService()}
+	if scope.Stage == "" {
+		// services only have resources within a stage, so events without a stage can only use resources of the project
+		scope.Service = ""
+	}
+	requestedResource, err := resolver.ResolveResource(scope, resource)
 
 	// return Nil in case resource couldn't be retrieved
-	if err != nil || requestedResource.ResourceContent == "" {
+	if err != nil || requestedResource.Resource.ResourceContent == "" {
 		return nil, fmt.Errorf("resource not found: %s - %s", resource, err)
 	}
 
-	return []byte(requestedResource.ResourceContent), nil
-}
-
-/**
- * Retrieves a resource (=file) from the local file system. Basically checks if the file is available and if so returns it
- */
-func getKeptnResourceFromLocal(resource string) ([]byte, error) {
-	if _, err := os.Stat(resource); err == nil {
-		return []byte(resource), nil
-	} else {
-		return nil, err
-	}
+	return []byte(requestedResource.Resource.ResourceContent), nil
 }
 
 // ValidateKeptnEntityName checks whether the provided name represents a valid
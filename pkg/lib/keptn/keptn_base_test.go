@@ -1,14 +1,23 @@
 package keptn
 
 import (
+	b64 "encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/keptn/go-utils/pkg/api/models"
+	api "github.com/keptn/go-utils/pkg/api/utils"
 )
 
 // generateStringWithSpecialChars generates a string of the given length
@@ -113,6 +122,229 @@ indicators:
 	}
 }
 
+type testEventProperties struct {
+	project string
+	stage   string
+	service string
+	labels  map[string]string
+}
+
+func (e *testEventProperties) GetProject() string                 { return e.project }
+func (e *testEventProperties) GetStage() string                   { return e.stage }
+func (e *testEventProperties) GetService() string                 { return e.service }
+func (e *testEventProperties) GetLabels() map[string]string       { return e.labels }
+func (e *testEventProperties) SetProject(project string)          { e.project = project }
+func (e *testEventProperties) SetStage(stage string)              { e.stage = stage }
+func (e *testEventProperties) SetService(service string)          { e.service = service }
+func (e *testEventProperties) SetLabels(labels map[string]string) { e.labels = labels }
+
+// newTestConfigurationService returns a server serving the given resources, which are keyed by the path of their level
+// and their resource URI
+func newTestConfigurationService(resources map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := resources[r.URL.EscapedPath()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, _ := json.Marshal(models.Resource{ResourceContent: b64.StdEncoding.EncodeToString([]byte(content))})
+		w.Write(body)
+	}))
+}
+
+func TestGetKeptnResource(t *testing.T) {
+	ts := newTestConfigurationService(map[string]string{
+		"/v1/project/sockshop/resource/remediation.yaml":                        "project-remediation",
+		"/v1/project/sockshop/stage/dev/resource/slo.yaml":                      "stage-slo",
+		"/v1/project/sockshop/stage/dev/service/carts/resource/slo.yaml":        "service-slo",
+		"/v1/project/sockshop/stage/dev/service/carts/resource/values.yaml":     "service-values",
+		"/v1/project/sockshop/stage/staging/service/carts/resource/values.yaml": "staging-values",
+	})
+	defer ts.Close()
+
+	k := &KeptnBase{
+		Event:           &testEventProperties{project: "sockshop", stage: "dev", service: "carts"},
+		ResourceHandler: api.NewResourceHandler(ts.URL),
+	}
+
+	tests := []struct {
+		resource string
+		want     string
+		wantErr  bool
+	}{
+		{resource: "values.yaml", want: "service-values"},
+		{resource: "slo.yaml", want: "service-slo"},
+		{resource: "remediation.yaml", want: "project-remediation"},
+		{resource: "sli.yaml", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.resource, func(t *testing.T) {
+			got, err := k.GetKeptnResource(tt.resource)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetKeptnResource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("GetKeptnResource() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	k.Event.SetService("")
+	got, err := k.GetKeptnResource("slo.yaml")
+	if err != nil || string(got) != "stage-slo" {
+		t.Errorf("GetKeptnResource() without service got = %s, %v, want stage-slo", got, err)
+	}
+
+	k.Event = &testEventProperties{project: "sockshop", service: "carts"}
+	got, err = k.GetKeptnResource("remediation.yaml")
+	if err != nil || string(got) != "project-remediation" {
+		t.Errorf("GetKeptnResource() without stage got = %s, %v, want project-remediation", got, err)
+	}
+	if _, err := k.GetKeptnResource("values.yaml"); err == nil {
+		t.Errorf("GetKeptnResource() without stage returned a resource of a stage")
+	}
+}
+
+// writeLocalResources writes the given resources, which are keyed by their path below the directory, to the directory
+func writeLocalResources(t *testing.T, dir string, resources map[string]string) {
+	for resourcePath, content := range resources {
+		file := filepath.Join(dir, filepath.FromSlash(resourcePath))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGetKeptnResourceFromLocalFileSystem(t *testing.T) {
+	dir := t.TempDir()
+	writeLocalResources(t, dir, map[string]string{
+		"sockshop/resource/remediation.yaml":                           "project-remediation",
+		"sockshop/stage/dev/resource/slo.yaml":                         "stage-slo",
+		"sockshop/stage/dev/service/carts/resource/slo.yaml":           "service-slo",
+		"sockshop/stage/staging/service/carts/resource/slo.yaml":       "staging-slo",
+		"sockshop/resource/dynatrace/sli.yaml":                         "indicators:\n  throughput: project\n  error_rate: project",
+		"sockshop/stage/dev/service/carts/resource/dynatrace/sli.yaml": "indicators:\n  error_rate: service",
+	})
+
+	k := &KeptnBase{
+		Event:              &testEventProperties{project: "sockshop", stage: "dev", service: "carts"},
+		UseLocalFileSystem: true,
+		ResourceProvider:   api.NewLocalResourceReader(dir),
+	}
+
+	tests := []struct {
+		resource string
+		want     string
+		wantErr  bool
+	}{
+		{resource: "slo.yaml", want: "service-slo"},
+		{resource: "/slo.yaml", want: "service-slo"},
+		{resource: "remediation.yaml", want: "project-remediation"},
+		{resource: "values.yaml", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := k.GetKeptnResource(tt.resource)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("GetKeptnResource(%q) error = %v, wantErr %v", tt.resource, err, tt.wantErr)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("GetKeptnResource(%q) got = %s, want %s", tt.resource, got, tt.want)
+		}
+	}
+
+	SLIs, err := k.GetSLIConfiguration("sockshop", "dev", "carts", "dynatrace/sli.yaml")
+	if err != nil {
+		t.Fatalf("GetSLIConfiguration() error = %v", err)
+	}
+	want := map[string]string{"throughput": "project", "error_rate": "service"}
+	if !reflect.DeepEqual(SLIs, want) {
+		t.Errorf("GetSLIConfiguration() got = %v, want %v", SLIs, want)
+	}
+}
+
+func TestGetKeptnResourceFromLocalRejectsUnsafePaths(t *testing.T) {
+	dir := t.TempDir()
+	writeLocalResources(t, dir, map[string]string{
+		"sockshop/resource/shipyard.yaml": "kind: Shipyard",
+	})
+
+	k := &KeptnBase{
+		Event:              &testEventProperties{project: "sockshop", stage: "dev", service: "carts"},
+		UseLocalFileSystem: true,
+		ResourceProvider:   api.NewLocalResourceReader(dir),
+	}
+
+	tests := map[string]string{
+		"../../../../resource/shipyard.yaml": api.ResourceURIRuleNoParentReference,
+		"//etc/passwd":                       api.ResourceURIRuleNotAbsolute,
+		"slo.yaml\x00":                       api.ResourceURIRuleNoControlChars,
+	}
+	for resource, rule := range tests {
+		_, err := k.GetKeptnResource(resource)
+		if err == nil || !strings.Contains(err.Error(), rule) {
+			t.Errorf("GetKeptnResource(%q) error = %v, want error containing %q", resource, err, rule)
+		}
+	}
+}
+
+func TestGetResourceProvider(t *testing.T) {
+	k := &KeptnBase{Event: &testEventProperties{project: "sockshop", stage: "dev", service: "carts"}}
+	if provider := k.GetResourceProvider(); provider != nil {
+		t.Errorf("GetResourceProvider() got = %v, want nil", provider)
+	}
+	if _, err := k.GetKeptnResource("slo.yaml"); !errors.Is(err, ErrNoResourceProvider) {
+		t.Errorf("GetKeptnResource() error = %v, want ErrNoResourceProvider", err)
+	}
+	if _, err := k.GetSLIConfiguration("sockshop", "dev", "carts", "dynatrace/sli.yaml"); !errors.Is(err, ErrNoResourceProvider) {
+		t.Errorf("GetSLIConfiguration() error = %v, want ErrNoResourceProvider", err)
+	}
+
+	// the deprecated ResourceHandler is only used if no ResourceProvider is set
+	resourceHandler := api.NewResourceHandler("localhost")
+	k.ResourceHandler = resourceHandler
+	if provider := k.GetResourceProvider(); provider != resourceHandler {
+		t.Errorf("GetResourceProvider() got = %v, want the ResourceHandler", provider)
+	}
+	localResourceReader := api.NewLocalResourceReader("resources")
+	k.ResourceProvider = localResourceReader
+	if provider := k.GetResourceProvider(); provider != localResourceReader {
+		t.Errorf("GetResourceProvider() got = %v, want the ResourceProvider", provider)
+	}
+}
+
+func TestGetSLIConfiguration(t *testing.T) {
+	ts := newTestConfigurationService(map[string]string{
+		"/v1/project/sockshop/resource/dynatrace%2Fsli.yaml":                         "indicators:\n  throughput: project\n  error_rate: project",
+		"/v1/project/sockshop/stage/dev/resource/dynatrace%2Fsli.yaml":               "indicators:\n  error_rate: stage\n  response_time: stage",
+		"/v1/project/sockshop/stage/dev/service/carts/resource/dynatrace%2Fsli.yaml": "indicators:\n  response_time: service",
+	})
+	defer ts.Close()
+
+	k := &KeptnBase{ResourceHandler: api.NewResourceHandler(ts.URL)}
+
+	SLIs, err := k.GetSLIConfiguration("sockshop", "dev", "carts", "dynatrace/sli.yaml")
+	if err != nil {
+		t.Fatalf("GetSLIConfiguration() error = %v", err)
+	}
+	want := map[string]string{"throughput": "project", "error_rate": "stage", "response_time": "service"}
+	if !reflect.DeepEqual(SLIs, want) {
+		t.Errorf("GetSLIConfiguration() got = %v, want %v", SLIs, want)
+	}
+
+	SLIs, err = k.GetSLIConfiguration("sockshop", "", "", "dynatrace/sli.yaml")
+	if err != nil {
+		t.Fatalf("GetSLIConfiguration() error = %v", err)
+	}
+	want = map[string]string{"throughput": "project", "error_rate": "project"}
+	if !reflect.DeepEqual(SLIs, want) {
+		t.Errorf("GetSLIConfiguration() got = %v, want %v", SLIs, want)
+	}
+}
+
 func TestGetServiceEndpoint(t *testing.T) {
 	type args struct {
 		service string
@@ -0,0 +1,253 @@
+package keptn
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// secretReferenceRegex matches secret references of the form {{.secret.<name>.<key>}}. The name of the secret must not
+// contain dots, hence everything after the first dot following the name is considered as key, e.g. "tls.crt"
+var secretReferenceRegex = regexp.MustCompile(`\{\{\s*\.secret\.([a-z0-9](?:[-a-z0-9]*[a-z0-9])?)\.([-._a-zA-Z0-9]+)\s*\}\}`)
+
+var envVarNameRegex = regexp.MustCompile(`[^A-Z0-9_]`)
+
+// ErrSecretValueNotFound is returned by a SecretSource if the secret or its key does not exist
+var ErrSecretValueNotFound = errors.New("secret value not found")
+
+// SecretReference references the value of a key of a secret, e.g. {{.secret.dynatrace.DT_API_TOKEN}}
+type SecretReference struct {
+	Name string
+	Key  string
+}
+
+// String returns the reference in the syntax used in properties and configs
+func (r SecretReference) String() string {
+	return "{{.secret." + r.Name + "." + r.Key + "}}"
+}
+
+// SecretSource provides the values of secrets
+type SecretSource interface {
+	// GetSecretValue returns the value of the key of the secret or ErrSecretValueNotFound if it does not exist
+	GetSecretValue(name string, key string) (string, error)
+}
+
+// MapSecretSource is a SecretSource providing the values of a map, keyed by the names of the secrets and their keys.
+// It is mainly intended for tests
+type MapSecretSource map[string]map[string]string
+
+// GetSecretValue returns the value of the key of the secret
+func (m MapSecretSource) GetSecretValue(name string, key string) (string, error) {
+	value, ok := m[name][key]
+	if !ok {
+		return "", ErrSecretValueNotFound
+	}
+	return value, nil
+}
+
+// EnvSecretSource is a SecretSource reading the values of secrets from environment variables named
+// <prefix><name>_<key> in upper case, with all characters other than letters, digits and underscores replaced
+// by underscores, e.g. SECRET_DYNATRACE_DT_API_TOKEN for the key DT_API_TOKEN of the secret dynatrace and the prefix "SECRET_"
+type EnvSecretSource struct {
+	prefix string
+}
+
+// NewEnvSecretSource creates a new EnvSecretSource for environment variables with the given prefix
+func NewEnvSecretSource(prefix string) *EnvSecretSource {
+	return &EnvSecretSource{prefix: prefix}
+}
+
+// GetSecretValue returns the value of the environment variable of the key of the secret
+func (e *EnvSecretSource) GetSecretValue(name string, key string) (string, error) {
+	value, ok := os.LookupEnv(e.EnvVarName(name, key))
+	if !ok {
+		return "", ErrSecretValueNotFound
+	}
+	return value, nil
+}
+
+// EnvVarName returns the name of the environment variable containing the value of the key of the secret
+func (e *EnvSecretSource) EnvVarName(name string, key string) string {
+	return envVarNameRegex.ReplaceAllString(strings.ToUpper(e.prefix+name+"_"+key), "_")
+}
+
+// FileSecretSource is a SecretSource reading the values of secrets from files named <dir>/<name>/<key>,
+// which is the layout of Kubernetes secrets mounted as volumes into the directories <dir>/<name>
+type FileSecretSource struct {
+	dir string
+}
+
+// NewFileSecretSource creates a new FileSecretSource reading the secrets from the given directory
+func NewFileSecretSource(dir string) *FileSecretSource {
+	return &FileSecretSource{dir: dir}
+}
+
+// GetSecretValue returns the content of the file of the key of the secret
+func (f *FileSecretSource) GetSecretValue(name string, key string) (string, error) {
+	if name == "" || key == "" || key == "." || key == ".." || filepath.Base(name) != name || filepath.Base(key) != key {
+		return "", ErrSecretValueNotFound
+	}
+	content, err := ioutil.ReadFile(filepath.Join(f.dir, name, key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", ErrSecretValueNotFound
+		}
+		return "", err
+	}
+	return string(content), nil
+}
+
+// UnresolvedSecretReference is a secret reference whose value could not be retrieved
+type UnresolvedSecretReference struct {
+	SecretReference
+	Err error
+}
+
+// UnresolvedSecretReferencesError is returned if secret references could not be resolved
+type UnresolvedSecretReferencesError struct {
+	References []UnresolvedSecretReference
+}
+
+func (e *UnresolvedSecretReferencesError) Error() string {
+	unresolved := make([]string, 0, len(e.References))
+	for _, ref := range e.References {
+		unresolved = append(unresolved, fmt.Sprintf("%s (%s)", ref.SecretReference, ref.Err))
+	}
+	return "could not resolve secret references: " + strings.Join(unresolved, ", ")
+}
+
+// FindSecretReferences returns the secret references contained in the strings of the value, ordered by the names of
+// the secrets and their keys. The value can be anything decoded from JSON or YAML, e.g. the properties of a task,
+// or a struct, in which case its exported fields are scanned
+func FindSecretReferences(value interface{}) []SecretReference {
+	found := map[SecretReference]bool{}
+	walkStrings(reflect.ValueOf(value), func(s string) string {
+		for _, match := range secretReferenceRegex.FindAllStringSubmatch(s, -1) {
+			found[SecretReference{Name: match[1], Key: match[2]}] = true
+		}
+		return s
+	})
+	return sortedSecretReferences(found)
+}
+
+// ResolveSecretReferences returns a copy of the value with all secret references in its strings substituted by the
+// values provided by the source. The value is scanned the same way as by FindSecretReferences, and the returned copy
+// has the same type, e.g. *Config for a *Config. If any reference can not be resolved, an
+// UnresolvedSecretReferencesError listing all of them is returned
+func ResolveSecretReferences(value interface{}, source SecretSource) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	values := map[SecretReference]string{}
+	unresolved := map[SecretReference]error{}
+	resolved := walkStrings(reflect.ValueOf(value), func(s string) string {
+		return secretReferenceRegex.ReplaceAllStringFunc(s, func(match string) string {
+			groups := secretReferenceRegex.FindStringSubmatch(match)
+			ref := SecretReference{Name: groups[1], Key: groups[2]}
+			if secretValue, ok := values[ref]; ok {
+				return secretValue
+			}
+			if _, ok := unresolved[ref]; ok {
+				return match
+			}
+			secretValue, err := source.GetSecretValue(ref.Name, ref.Key)
+			if err != nil {
+				unresolved[ref] = err
+				return match
+			}
+			values[ref] = secretValue
+			return secretValue
+		})
+	})
+
+	if len(unresolved) > 0 {
+		refs := map[SecretReference]bool{}
+		for ref := range unresolved {
+			refs[ref] = true
+		}
+		resolveErr := &UnresolvedSecretReferencesError{}
+		for _, ref := range sortedSecretReferences(refs) {
+			resolveErr.References = append(resolveErr.References, UnresolvedSecretReference{SecretReference: ref, Err: unresolved[ref]})
+		}
+		return nil, resolveErr
+	}
+	return resolved.Interface(), nil
+}
+
+func sortedSecretReferences(refs map[SecretReference]bool) []SecretReference {
+	sorted := make([]SecretReference, 0, len(refs))
+	for ref := range refs {
+		sorted = append(sorted, ref)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Name != sorted[j].Name {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].Key < sorted[j].Key
+	})
+	return sorted
+}
+
+// walkStrings returns a copy of the value with fn applied to all strings contained in it, i.e. in pointers,
+// interfaces, exported struct fields, map values, slices and arrays. The value must not contain cycles
+func walkStrings(v reflect.Value, fn func(string) string) reflect.Value {
+	switch v.Kind() {
+	case reflect.String:
+		copied := reflect.New(v.Type()).Elem()
+		copied.SetString(fn(v.String()))
+		return copied
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		if v.Kind() == reflect.Ptr {
+			copied := reflect.New(v.Type().Elem())
+			copied.Elem().Set(walkStrings(v.Elem(), fn))
+			return copied
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(walkStrings(v.Elem(), fn))
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if copied.Field(i).CanSet() {
+				copied.Field(i).Set(walkStrings(v.Field(i), fn))
+			}
+		}
+		return copied
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), walkStrings(iter.Value(), fn))
+		}
+		return copied
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(walkStrings(v.Index(i), fn))
+		}
+		return copied
+	case reflect.Array:
+		copied := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(walkStrings(v.Index(i), fn))
+		}
+		return copied
+	}
+	return v
+}
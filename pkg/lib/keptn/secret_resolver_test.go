@@ -0,0 +1,161 @@
+package keptn
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+type testWebhookConfig struct {
+	URL     string
+	Headers map[string]string
+	Retries int
+	Nested  *testWebhookConfig
+	secret  string
+}
+
+const testTaskProperties = `
+url: https://{{.secret.dynatrace.DT_TENANT}}/api
+headers:
+  - name: Authorization
+    value: "Api-Token {{ .secret.dynatrace.DT_API_TOKEN }}"
+  - name: X-Cert
+    value: "{{.secret.tls.tls.crt}}"
+retries: 3
+`
+
+func TestFindSecretReferences(t *testing.T) {
+	var properties interface{}
+	if err := yaml.Unmarshal([]byte(testTaskProperties), &properties); err != nil {
+		t.Fatal(err)
+	}
+
+	got := FindSecretReferences(properties)
+	want := []SecretReference{
+		{Name: "dynatrace", Key: "DT_API_TOKEN"},
+		{Name: "dynatrace", Key: "DT_TENANT"},
+		{Name: "tls", Key: "tls.crt"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindSecretReferences() got = %v, want %v", got, want)
+	}
+
+	if got := FindSecretReferences(nil); len(got) != 0 {
+		t.Errorf("FindSecretReferences(nil) got = %v, want no references", got)
+	}
+}
+
+func TestResolveSecretReferences(t *testing.T) {
+	var properties interface{}
+	if err := yaml.Unmarshal([]byte(testTaskProperties), &properties); err != nil {
+		t.Fatal(err)
+	}
+	source := MapSecretSource{
+		"dynatrace": {"DT_TENANT": "abc.live.dynatrace.com", "DT_API_TOKEN": "token"},
+		"tls":       {"tls.crt": "cert"},
+	}
+
+	resolved, err := ResolveSecretReferences(properties, source)
+	if err != nil {
+		t.Fatalf("ResolveSecretReferences() error = %v", err)
+	}
+	want := map[string]interface{}{
+		"url": "https://abc.live.dynatrace.com/api",
+		"headers": []interface{}{
+			map[string]interface{}{"name": "Authorization", "value": "Api-Token token"},
+			map[string]interface{}{"name": "X-Cert", "value": "cert"},
+		},
+		"retries": 3,
+	}
+	if !reflect.DeepEqual(resolved, want) {
+		t.Errorf("ResolveSecretReferences() got = %v, want %v", resolved, want)
+	}
+	if len(FindSecretReferences(properties)) != 3 {
+		t.Errorf("ResolveSecretReferences() must not modify the original value")
+	}
+}
+
+func TestResolveSecretReferencesInStruct(t *testing.T) {
+	config := &testWebhookConfig{
+		URL:     "https://{{.secret.webhook.host}}",
+		Headers: map[string]string{"token": "{{.secret.webhook.token}}"},
+		Retries: 2,
+		Nested:  &testWebhookConfig{URL: "{{.secret.webhook.host}}/nested"},
+		secret:  "{{.secret.webhook.token}}",
+	}
+
+	resolved, err := ResolveSecretReferences(config, MapSecretSource{"webhook": {"host": "example.com", "token": "t0k3n"}})
+	if err != nil {
+		t.Fatalf("ResolveSecretReferences() error = %v", err)
+	}
+	got := resolved.(*testWebhookConfig)
+	if got.URL != "https://example.com" || got.Headers["token"] != "t0k3n" || got.Retries != 2 || got.Nested.URL != "example.com/nested" {
+		t.Errorf("ResolveSecretReferences() got = %+v", got)
+	}
+	if got.secret != "{{.secret.webhook.token}}" {
+		t.Errorf("ResolveSecretReferences() must not resolve unexported fields, got = %s", got.secret)
+	}
+	if config.URL != "https://{{.secret.webhook.host}}" {
+		t.Errorf("ResolveSecretReferences() must not modify the original value")
+	}
+}
+
+func TestResolveSecretReferencesReportsUnresolvedReferences(t *testing.T) {
+	properties := []interface{}{"{{.secret.a.key}}", "{{.secret.b.key}} {{.secret.a.key}}", "{{.secret.c.key}}"}
+
+	_, err := ResolveSecretReferences(properties, MapSecretSource{"c": {"key": "value"}})
+	var unresolvedErr *UnresolvedSecretReferencesError
+	if !errors.As(err, &unresolvedErr) {
+		t.Fatalf("ResolveSecretReferences() error = %v, want UnresolvedSecretReferencesError", err)
+	}
+	if len(unresolvedErr.References) != 2 || unresolvedErr.References[0].Name != "a" || unresolvedErr.References[1].Name != "b" {
+		t.Errorf("ResolveSecretReferences() unresolved references = %v", unresolvedErr.References)
+	}
+	if !errors.Is(unresolvedErr.References[0].Err, ErrSecretValueNotFound) {
+		t.Errorf("ResolveSecretReferences() unresolved reference error = %v", unresolvedErr.References[0].Err)
+	}
+	if want := "could not resolve secret references: {{.secret.a.key}} (secret value not found), {{.secret.b.key}} (secret value not found)"; err.Error() != want {
+		t.Errorf("ResolveSecretReferences() error = %s, want %s", err, want)
+	}
+}
+
+func TestEnvSecretSource(t *testing.T) {
+	source := NewEnvSecretSource("SECRET_")
+	if got := source.EnvVarName("my-secret", "tls.crt"); got != "SECRET_MY_SECRET_TLS_CRT" {
+		t.Errorf("EnvVarName() got = %s", got)
+	}
+
+	os.Setenv("SECRET_MY_SECRET_TOKEN", "value")
+	defer os.Unsetenv("SECRET_MY_SECRET_TOKEN")
+	if got, err := source.GetSecretValue("my-secret", "token"); err != nil || got != "value" {
+		t.Errorf("GetSecretValue() got = %s, %v", got, err)
+	}
+	if _, err := source.GetSecretValue("my-secret", "missing"); err != ErrSecretValueNotFound {
+		t.Errorf("GetSecretValue() error = %v, want ErrSecretValueNotFound", err)
+	}
+}
+
+func TestFileSecretSource(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "dynatrace"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "dynatrace", "DT_API_TOKEN"), []byte("token"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	source := NewFileSecretSource(dir)
+	if got, err := source.GetSecretValue("dynatrace", "DT_API_TOKEN"); err != nil || got != "token" {
+		t.Errorf("GetSecretValue() got = %s, %v", got, err)
+	}
+	for _, ref := range []SecretReference{{Name: "dynatrace", Key: "DT_TENANT"}, {Name: "missing", Key: "key"}, {Name: "dynatrace", Key: ".."}} {
+		if _, err := source.GetSecretValue(ref.Name, ref.Key); err != ErrSecretValueNotFound {
+			t.Errorf("GetSecretValue(%s) error = %v, want ErrSecretValueNotFound", ref, err)
+		}
+	}
+}
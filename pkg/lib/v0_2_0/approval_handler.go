@@ -0,0 +1,127 @@
+package v0_2_0
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	api "github.com/keptn/go-utils/pkg/api/utils"
+)
+
+// ErrApprovalNotFound is returned if no open approval.triggered event with the requested ID exists
+var ErrApprovalNotFound = errors.New("open approval not found")
+
+// APIEventSender sends Keptn events via the api-service, e.g. by using api.APIHandler
+type APIEventSender interface {
+	SendEvent(event models.KeptnContextExtendedCE) (*models.EventContext, *models.Error)
+}
+
+// OpenApproval is an approval.triggered event that has not been answered by an approval.finished event yet
+type OpenApproval struct {
+	// Event is the approval.triggered event
+	Event *models.KeptnContextExtendedCE
+	// Data is the decoded payload of the approval.triggered event
+	Data ApprovalTriggeredEventData
+}
+
+// ApprovalHandler lists open approvals and approves or declines them by sending approval.finished events
+type ApprovalHandler struct {
+	shipyardControlHandler api.ShipyardControlInterface
+	eventSender            APIEventSender
+	source                 string
+}
+
+// NewApprovalHandler creates a new ApprovalHandler. The given source is used as the source of all sent approval.finished events
+func NewApprovalHandler(shipyardControlHandler api.ShipyardControlInterface, eventSender APIEventSender, source string) *ApprovalHandler {
+	return &ApprovalHandler{
+		shipyardControlHandler: shipyardControlHandler,
+		eventSender:            eventSender,
+		source:                 source,
+	}
+}
+
+// GetOpenApprovals returns all open approvals of the given project. Stage and service are optional and can be used to narrow down the result
+func (a *ApprovalHandler) GetOpenApprovals(project, stage, service string) ([]*OpenApproval, error) {
+	if project == "" {
+		return nil, errors.New("project must be specified")
+	}
+	events, err := a.shipyardControlHandler.GetOpenTriggeredEvents(api.EventFilter{
+		Project:   project,
+		Stage:     stage,
+		Service:   service,
+		EventType: GetTriggeredEventType(ApprovalTaskName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve open approvals: %s", err.Error())
+	}
+
+	approvals := []*OpenApproval{}
+	for _, event := range events {
+		approval := &OpenApproval{Event: event}
+		if err := event.DataAs(&approval.Data); err != nil {
+			return nil, fmt.Errorf("could not decode approval.triggered event %s: %s", event.ID, err.Error())
+		}
+		approvals = append(approvals, approval)
+	}
+	return approvals, nil
+}
+
+// GetOpenApproval returns the open approval with the given approval.triggered event ID.
+// If no such approval exists, ErrApprovalNotFound is returned
+func (a *ApprovalHandler) GetOpenApproval(project, stage, service, triggeredID string) (*OpenApproval, error) {
+	approvals, err := a.GetOpenApprovals(project, stage, service)
+	if err != nil {
+		return nil, err
+	}
+	for _, approval := range approvals {
+		if approval.Event.ID == triggeredID {
+			return approval, nil
+		}
+	}
+	return nil, ErrApprovalNotFound
+}
+
+// Approve sends an approval.finished event with result "pass" for the given open approval
+func (a *ApprovalHandler) Approve(approval *OpenApproval, message string) (*models.EventContext, error) {
+	return a.sendApprovalFinishedEvent(approval, ResultPass, message)
+}
+
+// Decline sends an approval.finished event with result "fail" for the given open approval
+func (a *ApprovalHandler) Decline(approval *OpenApproval, message string) (*models.EventContext, error) {
+	return a.sendApprovalFinishedEvent(approval, ResultFailed, message)
+}
+
+func (a *ApprovalHandler) sendApprovalFinishedEvent(approval *OpenApproval, result ResultType, message string) (*models.EventContext, error) {
+	if approval == nil || approval.Event == nil {
+		return nil, errors.New("no approval.triggered event provided")
+	}
+	if approval.Event.ID == "" || approval.Event.Shkeptncontext == "" {
+		return nil, errors.New("approval.triggered event must contain an id and a shkeptncontext")
+	}
+
+	finishedEventData := ApprovalFinishedEventData{
+		EventData: EventData{
+			Project: approval.Data.Project,
+			Stage:   approval.Data.Stage,
+			Service: approval.Data.Service,
+			Labels:  approval.Data.Labels,
+			Status:  StatusSucceeded,
+			Result:  result,
+			Message: message,
+		},
+	}
+
+	event, err := KeptnEvent(GetFinishedEventType(ApprovalTaskName), a.source, finishedEventData).
+		WithKeptnContext(approval.Event.Shkeptncontext).
+		WithTriggeredID(approval.Event.ID).
+		Build()
+	if err != nil {
+		return nil, fmt.Errorf("could not create approval.finished event: %s", err.Error())
+	}
+
+	eventContext, errObj := a.eventSender.SendEvent(event)
+	if errObj != nil {
+		return nil, fmt.Errorf("could not send approval.finished event: %s", errObj.GetMessage())
+	}
+	return eventContext, nil
+}
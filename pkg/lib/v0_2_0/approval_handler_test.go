@@ -0,0 +1,167 @@
+package v0_2_0
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	api "github.com/keptn/go-utils/pkg/api/utils"
+	"github.com/keptn/go-utils/pkg/common/strutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeShipyardControlHandler struct {
+	events     []*models.KeptnContextExtendedCE
+	err        error
+	lastFilter api.EventFilter
+}
+
+func (f *fakeShipyardControlHandler) GetOpenTriggeredEvents(filter api.EventFilter) ([]*models.KeptnContextExtendedCE, error) {
+	f.lastFilter = filter
+	return f.events, f.err
+}
+
+type fakeAPIEventSender struct {
+	sentEvents []models.KeptnContextExtendedCE
+	err        *models.Error
+}
+
+func (f *fakeAPIEventSender) SendEvent(event models.KeptnContextExtendedCE) (*models.EventContext, *models.Error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.sentEvents = append(f.sentEvents, event)
+	return &models.EventContext{KeptnContext: strutils.Stringp(event.Shkeptncontext)}, nil
+}
+
+func newApprovalTriggeredEvent(id, keptnContext string) *models.KeptnContextExtendedCE {
+	return &models.KeptnContextExtendedCE{
+		ID:             id,
+		Shkeptncontext: keptnContext,
+		Type:           strutils.Stringp(GetTriggeredEventType(ApprovalTaskName)),
+		Source:         strutils.Stringp("shipyard-controller"),
+		Data: ApprovalTriggeredEventData{
+			EventData: EventData{
+				Project: "sockshop",
+				Stage:   "hardening",
+				Service: "carts",
+				Labels:  map[string]string{"buildId": "1"},
+			},
+			Approval: Approval{Pass: ApprovalManual, Warning: ApprovalManual},
+		},
+	}
+}
+
+func TestApprovalHandler_GetOpenApprovals(t *testing.T) {
+	shipyardControl := &fakeShipyardControlHandler{
+		events: []*models.KeptnContextExtendedCE{
+			newApprovalTriggeredEvent("id-1", "ctx-1"),
+			newApprovalTriggeredEvent("id-2", "ctx-2"),
+		},
+	}
+	handler := NewApprovalHandler(shipyardControl, &fakeAPIEventSender{}, "chatops")
+
+	approvals, err := handler.GetOpenApprovals("sockshop", "hardening", "carts")
+	require.Nil(t, err)
+	require.Len(t, approvals, 2)
+	assert.Equal(t, "id-1", approvals[0].Event.ID)
+	assert.Equal(t, "carts", approvals[0].Data.Service)
+	assert.Equal(t, ApprovalManual, approvals[0].Data.Approval.Pass)
+	assert.Equal(t, api.EventFilter{
+		Project:   "sockshop",
+		Stage:     "hardening",
+		Service:   "carts",
+		EventType: "sh.keptn.event.approval.triggered",
+	}, shipyardControl.lastFilter)
+}
+
+func TestApprovalHandler_GetOpenApprovalsErrors(t *testing.T) {
+	handler := NewApprovalHandler(&fakeShipyardControlHandler{}, &fakeAPIEventSender{}, "chatops")
+	_, err := handler.GetOpenApprovals("", "", "")
+	assert.NotNil(t, err)
+
+	handler = NewApprovalHandler(&fakeShipyardControlHandler{err: errors.New("oops")}, &fakeAPIEventSender{}, "chatops")
+	_, err = handler.GetOpenApprovals("sockshop", "", "")
+	assert.NotNil(t, err)
+}
+
+func TestApprovalHandler_GetOpenApproval(t *testing.T) {
+	shipyardControl := &fakeShipyardControlHandler{
+		events: []*models.KeptnContextExtendedCE{
+			newApprovalTriggeredEvent("id-1", "ctx-1"),
+		},
+	}
+	handler := NewApprovalHandler(shipyardControl, &fakeAPIEventSender{}, "chatops")
+
+	approval, err := handler.GetOpenApproval("sockshop", "", "", "id-1")
+	require.Nil(t, err)
+	assert.Equal(t, "ctx-1", approval.Event.Shkeptncontext)
+
+	_, err = handler.GetOpenApproval("sockshop", "", "", "id-unknown")
+	assert.Equal(t, ErrApprovalNotFound, err)
+}
+
+func TestApprovalHandler_ApproveAndDecline(t *testing.T) {
+	tests := []struct {
+		name       string
+		approve    bool
+		wantResult ResultType
+	}{
+		{name: "approve", approve: true, wantResult: ResultPass},
+		{name: "decline", approve: false, wantResult: ResultFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shipyardControl := &fakeShipyardControlHandler{
+				events: []*models.KeptnContextExtendedCE{newApprovalTriggeredEvent("id-1", "ctx-1")},
+			}
+			sender := &fakeAPIEventSender{}
+			handler := NewApprovalHandler(shipyardControl, sender, "chatops")
+
+			approvals, err := handler.GetOpenApprovals("sockshop", "", "")
+			require.Nil(t, err)
+
+			var eventContext *models.EventContext
+			if tt.approve {
+				eventContext, err = handler.Approve(approvals[0], "lgtm")
+			} else {
+				eventContext, err = handler.Decline(approvals[0], "not today")
+			}
+			require.Nil(t, err)
+			assert.Equal(t, "ctx-1", *eventContext.KeptnContext)
+
+			require.Len(t, sender.sentEvents, 1)
+			sent := sender.sentEvents[0]
+			assert.Equal(t, "sh.keptn.event.approval.finished", *sent.Type)
+			assert.Equal(t, "chatops", *sent.Source)
+			assert.Equal(t, "ctx-1", sent.Shkeptncontext)
+			assert.Equal(t, "id-1", sent.Triggeredid)
+
+			data := ApprovalFinishedEventData{}
+			require.Nil(t, sent.DataAs(&data))
+			assert.Equal(t, "sockshop", data.Project)
+			assert.Equal(t, "hardening", data.Stage)
+			assert.Equal(t, "carts", data.Service)
+			assert.Equal(t, map[string]string{"buildId": "1"}, data.Labels)
+			assert.Equal(t, StatusSucceeded, data.Status)
+			assert.Equal(t, tt.wantResult, data.Result)
+		})
+	}
+}
+
+func TestApprovalHandler_ApproveErrors(t *testing.T) {
+	handler := NewApprovalHandler(&fakeShipyardControlHandler{}, &fakeAPIEventSender{}, "chatops")
+	_, err := handler.Approve(nil, "")
+	assert.NotNil(t, err)
+
+	_, err = handler.Approve(&OpenApproval{Event: &models.KeptnContextExtendedCE{ID: "id-1"}}, "")
+	assert.NotNil(t, err)
+
+	failingSender := &fakeAPIEventSender{err: &models.Error{Message: strutils.Stringp("oops")}}
+	handler = NewApprovalHandler(&fakeShipyardControlHandler{}, failingSender, "chatops")
+	approval := &OpenApproval{Event: newApprovalTriggeredEvent("id-1", "ctx-1")}
+	require.Nil(t, approval.Event.DataAs(&approval.Data))
+	_, err = handler.Approve(approval, "")
+	assert.EqualError(t, err, "could not send approval.finished event: oops")
+}
@@ -0,0 +1,305 @@
+package v0_2_0
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	httpprotocol "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/keptn/go-utils/pkg/api/models"
+	api "github.com/keptn/go-utils/pkg/api/utils"
+)
+
+// DefaultReceiverPort is the port the EventReceiver listens on by default
+const DefaultReceiverPort = "8080"
+
+// DefaultEventPath is the path the EventReceiver accepts events on by default
+const DefaultEventPath = "/"
+
+// HealthPath is the path of the health endpoint of the EventReceiver
+const HealthPath = "/health"
+
+// ReadinessPath is the path of the readiness endpoint of the EventReceiver
+const ReadinessPath = "/ready"
+
+// ErrReceiverShuttingDown is returned if an event is received while the EventReceiver is shutting down
+var ErrReceiverShuttingDown = errors.New("event receiver is shutting down")
+
+// KeptnEventHandlerFunc handles an event received by the EventReceiver
+type KeptnEventHandlerFunc func(event models.KeptnContextExtendedCE) error
+
+type eventHandlerRegistration struct {
+	// subscription returns the subscription the handler is registered for. It is called for each received event,
+	// so that it reflects changes, e.g. tasks registered at a TaskHandler after calling HandleTasks
+	subscription func() models.Subscription
+	handler      KeptnEventHandlerFunc
+}
+
+// EventReceiver accepts CloudEvents in structured and binary mode via HTTP and dispatches them to the handlers
+// registered for their type. Besides the event endpoint, it serves a health endpoint and a readiness endpoint,
+// which reports the receiver as not ready while it is shutting down or if a readiness check fails.
+// Events are handled asynchronously, and Shutdown waits for events which are still being handled.
+// By default, each event is handled in its own goroutine. With WithWorkerPool, the events are handled by a WorkerPool
+// instead, so that events of the same Keptn context are handled in the order they were received
+type EventReceiver struct {
+	port           string
+	eventPath      string
+	readinessCheck func() error
+	workerPool     *WorkerPool
+
+	mu           sync.RWMutex
+	handlers     []eventHandlerRegistration
+	shuttingDown bool
+	inFlight     sync.WaitGroup
+	server       *http.Server
+}
+
+// EventReceiverOption can be used to configure an EventReceiver
+type EventReceiverOption func(*EventReceiver)
+
+// WithReceiverPort sets the port the EventReceiver listens on
+func WithReceiverPort(port string) EventReceiverOption {
+	return func(r *EventReceiver) {
+		r.port = port
+	}
+}
+
+// WithEventPath sets the path the EventReceiver accepts events on
+func WithEventPath(path string) EventReceiverOption {
+	return func(r *EventReceiver) {
+		r.eventPath = path
+	}
+}
+
+// WithReadinessCheck sets a check that is executed on each request to the readiness endpoint.
+// If it returns an error, the EventReceiver is reported as not ready
+func WithReadinessCheck(check func() error) EventReceiverOption {
+	return func(r *EventReceiver) {
+		r.readinessCheck = check
+	}
+}
+
+// WithWorkerPool sets the WorkerPool which handles the received events, using their shkeptncontext as key.
+// If the queue of the WorkerPool is full, the event is rejected with the status 429 Too Many Requests.
+// The WorkerPool is shut down together with the EventReceiver. Since queued events have already been accepted,
+// Shutdown runs them regardless of WithDrainOnShutdown
+func WithWorkerPool(pool *WorkerPool) EventReceiverOption {
+	return func(r *EventReceiver) {
+		r.workerPool = pool
+	}
+}
+
+// NewEventReceiver creates a new EventReceiver
+func NewEventReceiver(opts ...EventReceiverOption) *EventReceiver {
+	r := &EventReceiver{
+		port:      DefaultReceiverPort,
+		eventPath: DefaultEventPath,
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// Handle registers the handler for events whose type matches the given topic. The topic may contain the wildcards
+// "*" and ">", see SubscriptionMatcher
+func (r *EventReceiver) Handle(topic string, handler KeptnEventHandlerFunc) {
+	r.HandleSubscription(models.Subscription{Topics: []string{topic}}, handler)
+}
+
+// HandleSubscription registers the handler for events matching the given subscription, including its filter
+func (r *EventReceiver) HandleSubscription(subscription models.Subscription, handler KeptnEventHandlerFunc) {
+	r.handle(func() models.Subscription { return subscription }, handler)
+}
+
+// HandleTasks registers the given TaskHandler for the .triggered events of all tasks registered at the TaskHandler.
+// Tasks registered at the TaskHandler afterwards are handled as well
+func (r *EventReceiver) HandleTasks(taskHandler *TaskHandler) {
+	r.handle(func() models.Subscription {
+		return models.Subscription{Topics: taskHandler.Topics()}
+	}, func(event models.KeptnContextExtendedCE) error {
+		return taskHandler.Handle(ToCloudEvent(event))
+	})
+}
+
+func (r *EventReceiver) handle(subscription func() models.Subscription, handler KeptnEventHandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers = append(r.handlers, eventHandlerRegistration{subscription: subscription, handler: handler})
+}
+
+// ListenAndServe starts the HTTP server of the EventReceiver and blocks until the server is shut down
+func (r *EventReceiver) ListenAndServe() error {
+	r.mu.Lock()
+	if r.shuttingDown {
+		r.mu.Unlock()
+		return ErrReceiverShuttingDown
+	}
+	r.server = &http.Server{Addr: fmt.Sprintf(":%s", r.port), Handler: r}
+	server := r.server
+	r.mu.Unlock()
+
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting new events, shuts down the HTTP server and waits until all accepted events have been handled,
+// or until the context is done
+func (r *EventReceiver) Shutdown(ctx context.Context) error {
+	r.mu.Lock()
+	r.shuttingDown = true
+	server := r.server
+	r.mu.Unlock()
+
+	if server != nil {
+		if err := server.Shutdown(ctx); err != nil {
+			return err
+		}
+	}
+	if r.workerPool != nil {
+		if err := r.workerPool.shutdown(ctx, true); err != nil {
+			return err
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		r.inFlight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("events are still being handled: %w", ctx.Err())
+	}
+}
+
+// ServeHTTP serves the event, health and readiness endpoints of the EventReceiver
+func (r *EventReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch {
+	case req.URL.Path == HealthPath && req.Method == http.MethodGet:
+		writeStatus(w, http.StatusOK, "OK")
+	case req.URL.Path == ReadinessPath && req.Method == http.MethodGet:
+		r.serveReadiness(w)
+	case req.URL.Path == r.eventPath && req.Method == http.MethodPost:
+		r.serveEvent(w, req)
+	case req.URL.Path == r.eventPath || req.URL.Path == HealthPath || req.URL.Path == ReadinessPath:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (r *EventReceiver) serveReadiness(w http.ResponseWriter) {
+	r.mu.RLock()
+	shuttingDown := r.shuttingDown
+	r.mu.RUnlock()
+	if shuttingDown {
+		writeStatus(w, http.StatusServiceUnavailable, ErrReceiverShuttingDown.Error())
+		return
+	}
+	if r.readinessCheck != nil {
+		if err := r.readinessCheck(); err != nil {
+			writeStatus(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+	}
+	writeStatus(w, http.StatusOK, "OK")
+}
+
+func (r *EventReceiver) serveEvent(w http.ResponseWriter, req *http.Request) {
+	event, err := binding.ToEvent(req.Context(), httpprotocol.NewMessageFromHttpRequest(req))
+	if err != nil {
+		writeStatus(w, http.StatusBadRequest, fmt.Sprintf("could not read CloudEvent: %s", err.Error()))
+		return
+	}
+	if err := event.Validate(); err != nil {
+		writeStatus(w, http.StatusBadRequest, fmt.Sprintf("invalid CloudEvent: %s", err.Error()))
+		return
+	}
+	keptnEvent, err := ToKeptnEvent(*event)
+	if err != nil {
+		writeStatus(w, http.StatusBadRequest, fmt.Sprintf("could not convert CloudEvent: %s", err.Error()))
+		return
+	}
+	if err := r.dispatch(keptnEvent); err != nil {
+		statusCode := http.StatusServiceUnavailable
+		if errors.Is(err, ErrQueueFull) {
+			statusCode = http.StatusTooManyRequests
+		}
+		writeStatus(w, statusCode, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// dispatch starts handling the event with all matching handlers, unless the receiver is shutting down
+func (r *EventReceiver) dispatch(event models.KeptnContextExtendedCE) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.shuttingDown {
+		return ErrReceiverShuttingDown
+	}
+
+	data := EventData{}
+	if err := EventDataAs(event, &data); err != nil {
+		log.Printf("could not decode data of event %s: %s", event.ID, err.Error())
+	}
+	handlers := []KeptnEventHandlerFunc{}
+	for _, registration := range r.handlers {
+		if MatchesSubscription(registration.subscription(), *event.Type, data) {
+			handlers = append(handlers, registration.handler)
+		}
+	}
+	if len(handlers) == 0 {
+		return nil
+	}
+
+	if r.workerPool != nil {
+		key := event.Shkeptncontext
+		if key == "" {
+			key = event.ID
+		}
+		return r.workerPool.Submit(key, func() {
+			for _, handler := range handlers {
+				handleEvent(handler, event)
+			}
+		})
+	}
+	for _, handler := range handlers {
+		r.inFlight.Add(1)
+		go func(handler KeptnEventHandlerFunc) {
+			defer r.inFlight.Done()
+			handleEvent(handler, event)
+		}(handler)
+	}
+	return nil
+}
+
+func handleEvent(handler KeptnEventHandlerFunc, event models.KeptnContextExtendedCE) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Printf("handler of event %s of type %s panicked: %v", event.ID, *event.Type, rec)
+		}
+	}()
+	if err := handler(event); err != nil {
+		log.Printf("could not handle event %s of type %s: %s", event.ID, *event.Type, err.Error())
+	}
+}
+
+func writeStatus(w http.ResponseWriter, statusCode int, status string) {
+	body, _ := json.Marshal(api.StatusBody{Status: status})
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(statusCode)
+	if _, err := w.Write(body); err != nil {
+		log.Println(err)
+	}
+}
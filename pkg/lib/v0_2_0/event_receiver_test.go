@@ -0,0 +1,204 @@
+package v0_2_0
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/go-utils/pkg/lib/keptn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type receivedEvents struct {
+	mu     sync.Mutex
+	events []models.KeptnContextExtendedCE
+	done   chan struct{}
+}
+
+func newReceivedEvents() *receivedEvents {
+	return &receivedEvents{done: make(chan struct{}, 10)}
+}
+
+func (r *receivedEvents) handle(event models.KeptnContextExtendedCE) error {
+	r.mu.Lock()
+	r.events = append(r.events, event)
+	r.mu.Unlock()
+	r.done <- struct{}{}
+	return nil
+}
+
+func (r *receivedEvents) wait(t *testing.T, n int) []models.KeptnContextExtendedCE {
+	for i := 0; i < n; i++ {
+		select {
+		case <-r.done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected %d events, received %d", n, i)
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.events
+}
+
+func TestEventReceiverStructuredMode(t *testing.T) {
+	received := newReceivedEvents()
+	receiver := NewEventReceiver()
+	receiver.Handle("sh.keptn.event.*.triggered", received.handle)
+	ts := httptest.NewServer(receiver)
+	defer ts.Close()
+
+	sender, err := NewHTTPEventSender(ts.URL)
+	require.Nil(t, err)
+	event, err := KeptnEvent(GetTriggeredEventType(TestTaskName), "shipyard-controller", EventData{Project: "sockshop", Stage: "dev", Service: "carts"}).
+		WithKeptnContext("my-context").Build()
+	require.Nil(t, err)
+	require.Nil(t, sender.SendEvent(ToCloudEvent(event)))
+
+	events := received.wait(t, 1)
+	assert.Equal(t, event.ID, events[0].ID)
+	assert.Equal(t, "my-context", events[0].Shkeptncontext)
+	data := EventData{}
+	require.Nil(t, EventDataAs(events[0], &data))
+	assert.Equal(t, "carts", data.Service)
+}
+
+func TestEventReceiverBinaryMode(t *testing.T) {
+	received := newReceivedEvents()
+	ignored := newReceivedEvents()
+	receiver := NewEventReceiver(WithEventPath("/events"))
+	receiver.HandleSubscription(models.Subscription{
+		Topics: []string{"sh.keptn.event.>"},
+		Filter: models.SubscriptionFilter{Project: "sockshop"},
+	}, received.handle)
+	receiver.Handle("sh.keptn.event.deployment.triggered", ignored.handle)
+	ts := httptest.NewServer(receiver)
+	defer ts.Close()
+
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/events", strings.NewReader(`{"project":"sockshop","stage":"dev","service":"carts"}`))
+	require.Nil(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("ce-specversion", "1.0")
+	req.Header.Set("ce-id", "my-id")
+	req.Header.Set("ce-type", "sh.keptn.event.test.finished")
+	req.Header.Set("ce-source", "jmeter-service")
+	req.Header.Set("ce-shkeptncontext", "my-context")
+	resp, err := http.DefaultClient.Do(req)
+	require.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	events := received.wait(t, 1)
+	assert.Equal(t, "my-id", events[0].ID)
+	assert.Equal(t, "sh.keptn.event.test.finished", *events[0].Type)
+	assert.Equal(t, "my-context", events[0].Shkeptncontext)
+	assert.Empty(t, ignored.events)
+}
+
+func TestEventReceiverInvalidEvent(t *testing.T) {
+	ts := httptest.NewServer(NewEventReceiver())
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL, "application/json", strings.NewReader(`{"project":"sockshop"}`))
+	require.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Get(ts.URL)
+	require.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestEventReceiverHealthAndReadiness(t *testing.T) {
+	var readinessErr error
+	receiver := NewEventReceiver(WithReadinessCheck(func() error { return readinessErr }))
+	ts := httptest.NewServer(receiver)
+	defer ts.Close()
+
+	getStatus := func(path string) int {
+		resp, err := http.Get(ts.URL + path)
+		require.Nil(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, getStatus(HealthPath))
+	assert.Equal(t, http.StatusOK, getStatus(ReadinessPath))
+	assert.Equal(t, http.StatusNotFound, getStatus("/unknown"))
+
+	readinessErr = errors.New("not connected")
+	assert.Equal(t, http.StatusServiceUnavailable, getStatus(ReadinessPath))
+
+	readinessErr = nil
+	require.Nil(t, receiver.Shutdown(context.Background()))
+	assert.Equal(t, http.StatusServiceUnavailable, getStatus(ReadinessPath))
+	assert.Equal(t, http.StatusOK, getStatus(HealthPath))
+}
+
+func TestEventReceiverShutdownWaitsForInFlightEvents(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	receiver := NewEventReceiver()
+	receiver.Handle("sh.keptn.event.test.triggered", func(event models.KeptnContextExtendedCE) error {
+		close(started)
+		<-release
+		return nil
+	})
+	ts := httptest.NewServer(receiver)
+	defer ts.Close()
+
+	sender, err := NewHTTPEventSender(ts.URL)
+	require.Nil(t, err)
+	event, err := KeptnEvent(GetTriggeredEventType(TestTaskName), "shipyard-controller", EventData{Project: "sockshop", Stage: "dev", Service: "carts"}).Build()
+	require.Nil(t, err)
+	require.Nil(t, sender.SendEvent(ToCloudEvent(event)))
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.True(t, errors.Is(receiver.Shutdown(ctx), context.DeadlineExceeded))
+
+	resp, err := http.Post(ts.URL, "application/cloudevents+json", strings.NewReader(`{"specversion":"1.0","id":"1","type":"sh.keptn.event.test.triggered","source":"test"}`))
+	require.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	close(release)
+	assert.Nil(t, receiver.Shutdown(context.Background()))
+}
+
+func TestEventReceiverHandleTasks(t *testing.T) {
+	sender := &TestSender{}
+	taskHandler := NewTaskHandler("my-service", WithTaskHandlerKeptnOpts(keptn.KeptnOpts{EventSender: sender}))
+	require.Nil(t, taskHandler.Register(TestTaskName, func(k *Keptn, data *TestTriggeredEventData) (*TestFinishedEventData, error) {
+		return nil, nil
+	}))
+	// events of the same context are handled one after another by the worker pool
+	receiver := NewEventReceiver(WithWorkerPool(NewWorkerPool(WithDrainOnShutdown())))
+	receiver.HandleTasks(taskHandler)
+
+	// tasks registered after calling HandleTasks are handled as well
+	require.Nil(t, taskHandler.Register("deployment", func(k *Keptn, data *DeploymentTriggeredEventData) (*DeploymentFinishedEventData, error) {
+		return nil, nil
+	}))
+
+	for _, task := range []string{TestTaskName, "deployment"} {
+		event, err := KeptnEvent(GetTriggeredEventType(task), "shipyard-controller", EventData{Project: "sockshop", Stage: "dev", Service: "carts"}).
+			WithKeptnContext("my-context").Build()
+		require.Nil(t, err)
+		require.Nil(t, receiver.dispatch(event))
+	}
+	require.Nil(t, receiver.Shutdown(context.Background()))
+
+	assert.Nil(t, sender.AssertSentEventTypes([]string{
+		"sh.keptn.event.test.started", "sh.keptn.event.test.finished",
+		"sh.keptn.event.deployment.started", "sh.keptn.event.deployment.finished",
+	}))
+}
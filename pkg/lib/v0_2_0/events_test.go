@@ -574,3 +574,16 @@ func TestReplaceEventTypeKind(t *testing.T) {
 		})
 	}
 }
+
+func TestSubscriptionTopicValidationMatchesIsValidEventType(t *testing.T) {
+	eventTypes := []string{
+		"sh.keptn.event.deployment.triggered",
+		"sh.keptn.event.dev.delivery.triggered",
+		"sh.keptn.event.deployment",
+		"sh.keptn.event..triggered",
+		"sh.keptn.event.dev.delivery.triggered.extra",
+	}
+	for _, eventType := range eventTypes {
+		assert.Equal(t, IsValidEventType(eventType), api.ValidateSubscriptionTopic(eventType) == nil, eventType)
+	}
+}
@@ -63,6 +63,18 @@ func NewKeptn(incomingEvent *cloudevents.Event, opts keptn.KeptnOpts) (*Keptn, e
 	}
 
 	k.ResourceHandler = api.NewResourceHandler(csURL)
+	switch {
+	case opts.ResourceProvider != nil:
+		k.ResourceProvider = opts.ResourceProvider
+	case opts.UseLocalFileSystem:
+		localResourceDirectory := keptn.DefaultLocalResourceDirectory
+		if opts.LocalResourceDirectory != "" {
+			localResourceDirectory = opts.LocalResourceDirectory
+		}
+		k.ResourceProvider = api.NewLocalResourceReader(localResourceDirectory)
+	default:
+		k.ResourceProvider = k.ResourceHandler
+	}
 	k.EventHandler = api.NewEventHandler(datastoreURL)
 
 	loggingServiceName := keptn.DefaultLoggingServiceName
@@ -76,7 +88,11 @@ func NewKeptn(incomingEvent *cloudevents.Event, opts keptn.KeptnOpts) (*Keptn, e
 
 // GetShipyard returns the shipyard definition of a project
 func (k *Keptn) GetShipyard() (*Shipyard, error) {
-	shipyardResource, err := k.ResourceHandler.GetProjectResource(k.Event.GetProject(), "shipyard.yaml")
+	resourceProvider := k.GetResourceProvider()
+	if resourceProvider == nil {
+		return nil, keptn.ErrNoResourceProvider
+	}
+	shipyardResource, err := resourceProvider.GetResource(api.ResourceScope{Project: k.Event.GetProject()}, "shipyard.yaml")
 	if err != nil {
 		return nil, err
 	}
@@ -199,7 +215,10 @@ func ensureContextAttributesAreSet(srcEvent, newEvent keptn.EventProperties) kep
 	newEvent.SetProject(srcEvent.GetProject())
 	newEvent.SetStage(srcEvent.GetStage())
 	newEvent.SetService(srcEvent.GetService())
-	labels := srcEvent.GetLabels()
+	labels := map[string]string{}
+	for key, value := range srcEvent.GetLabels() {
+		labels[key] = value
+	}
 
 	// make sure labels from triggered event are included. Existing labels cannot be changed, but new ones can be added
 	for key, value := range newEvent.GetLabels() {
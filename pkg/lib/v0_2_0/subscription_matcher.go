@@ -0,0 +1,75 @@
+package v0_2_0
+
+import (
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn/go-utils/pkg/api/models"
+	api "github.com/keptn/go-utils/pkg/api/utils"
+)
+
+// SubscriptionMatcher decides whether events match subscriptions, the same way the distributor forwards events to
+// integrations: the type of the event has to match a topic of the subscription, which may contain the wildcards "*"
+// for a single element and ">" for all remaining elements, e.g. "sh.keptn.event.*.triggered" or "sh.keptn.event.>".
+// Additionally, the project, stage and service of the event have to match the filter of the subscription, if set
+type SubscriptionMatcher struct {
+	subscriptions []models.Subscription
+}
+
+// NewSubscriptionMatcher creates a new SubscriptionMatcher for the given subscriptions
+func NewSubscriptionMatcher(subscriptions ...models.Subscription) *SubscriptionMatcher {
+	return &SubscriptionMatcher{subscriptions: subscriptions}
+}
+
+// Matches checks whether an event of the given type and data matches any of the subscriptions
+func (m *SubscriptionMatcher) Matches(eventType string, data EventData) bool {
+	return len(m.MatchingSubscriptions(eventType, data)) > 0
+}
+
+// MatchingSubscriptions returns the subscriptions an event of the given type and data matches
+func (m *SubscriptionMatcher) MatchingSubscriptions(eventType string, data EventData) []models.Subscription {
+	matching := []models.Subscription{}
+	for _, subscription := range m.subscriptions {
+		if MatchesSubscription(subscription, eventType, data) {
+			matching = append(matching, subscription)
+		}
+	}
+	return matching
+}
+
+// MatchesEvent checks whether the event matches any of the subscriptions
+func (m *SubscriptionMatcher) MatchesEvent(event models.KeptnContextExtendedCE) bool {
+	if event.Type == nil {
+		return false
+	}
+	data := EventData{}
+	if err := EventDataAs(event, &data); err != nil {
+		return false
+	}
+	return m.Matches(*event.Type, data)
+}
+
+// MatchesCloudEvent checks whether the cloud event matches any of the subscriptions
+func (m *SubscriptionMatcher) MatchesCloudEvent(event cloudevents.Event) bool {
+	data := EventData{}
+	if len(event.Data()) > 0 {
+		if err := event.DataAs(&data); err != nil {
+			return false
+		}
+	}
+	return m.Matches(event.Type(), data)
+}
+
+// MatchesSubscription checks whether an event of the given type and data matches the subscription
+func MatchesSubscription(subscription models.Subscription, eventType string, data EventData) bool {
+	filter := subscription.Filter
+	if (filter.Project != "" && filter.Project != data.Project) ||
+		(filter.Stage != "" && filter.Stage != data.Stage) ||
+		(filter.Service != "" && filter.Service != data.Service) {
+		return false
+	}
+	for _, topic := range subscription.Topics {
+		if api.MatchSubscriptionTopic(topic, eventType) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package v0_2_0
+
+import (
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchesSubscription(t *testing.T) {
+	tests := []struct {
+		name         string
+		subscription models.Subscription
+		eventType    string
+		data         EventData
+		want         bool
+	}{
+		{
+			name:         "exact topic",
+			subscription: models.Subscription{Topics: []string{"sh.keptn.event.test.triggered"}},
+			eventType:    "sh.keptn.event.test.triggered",
+			want:         true,
+		},
+		{
+			name:         "other topic",
+			subscription: models.Subscription{Topics: []string{"sh.keptn.event.test.triggered"}},
+			eventType:    "sh.keptn.event.test.finished",
+		},
+		{
+			name:         "single element wildcard",
+			subscription: models.Subscription{Topics: []string{"sh.keptn.event.*.triggered"}},
+			eventType:    "sh.keptn.event.deployment.triggered",
+			want:         true,
+		},
+		{
+			name:         "single element wildcard does not match sequence events",
+			subscription: models.Subscription{Topics: []string{"sh.keptn.event.*.triggered"}},
+			eventType:    "sh.keptn.event.dev.delivery.triggered",
+		},
+		{
+			name:         "remaining elements wildcard",
+			subscription: models.Subscription{Topics: []string{"sh.keptn.event.>"}},
+			eventType:    "sh.keptn.event.dev.delivery.triggered",
+			want:         true,
+		},
+		{
+			name:         "any of multiple topics",
+			subscription: models.Subscription{Topics: []string{"sh.keptn.event.test.triggered", "sh.keptn.event.evaluation.triggered"}},
+			eventType:    "sh.keptn.event.evaluation.triggered",
+			want:         true,
+		},
+		{
+			name: "matching filter",
+			subscription: models.Subscription{
+				Topics: []string{"sh.keptn.event.>"},
+				Filter: models.SubscriptionFilter{Project: "sockshop", Stage: "dev", Service: "carts"},
+			},
+			eventType: "sh.keptn.event.test.triggered",
+			data:      EventData{Project: "sockshop", Stage: "dev", Service: "carts"},
+			want:      true,
+		},
+		{
+			name: "other stage",
+			subscription: models.Subscription{
+				Topics: []string{"sh.keptn.event.>"},
+				Filter: models.SubscriptionFilter{Project: "sockshop", Stage: "dev"},
+			},
+			eventType: "sh.keptn.event.test.triggered",
+			data:      EventData{Project: "sockshop", Stage: "production", Service: "carts"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchesSubscription(tt.subscription, tt.eventType, tt.data))
+		})
+	}
+}
+
+func TestSubscriptionMatcher(t *testing.T) {
+	testSubscription := models.Subscription{Topics: []string{"sh.keptn.event.test.triggered"}}
+	devSubscription := models.Subscription{Topics: []string{"sh.keptn.event.>"}, Filter: models.SubscriptionFilter{Project: "sockshop", Stage: "dev"}}
+	matcher := NewSubscriptionMatcher(testSubscription, devSubscription)
+
+	data := EventData{Project: "sockshop", Stage: "dev", Service: "carts"}
+	assert.Equal(t, []models.Subscription{testSubscription, devSubscription}, matcher.MatchingSubscriptions("sh.keptn.event.test.triggered", data))
+	assert.Equal(t, []models.Subscription{devSubscription}, matcher.MatchingSubscriptions("sh.keptn.event.deployment.triggered", data))
+
+	event, err := KeptnEvent("sh.keptn.event.deployment.triggered", "source", data).Build()
+	require.Nil(t, err)
+	assert.True(t, matcher.MatchesEvent(event))
+
+	event, err = KeptnEvent("sh.keptn.event.deployment.triggered", "source", EventData{Project: "sockshop", Stage: "production", Service: "carts"}).Build()
+	require.Nil(t, err)
+	assert.False(t, matcher.MatchesEvent(event))
+
+	ce := cloudevents.NewEvent()
+	ce.SetType("sh.keptn.event.test.triggered")
+	require.Nil(t, ce.SetData(cloudevents.ApplicationJSON, EventData{Project: "podtato"}))
+	assert.True(t, matcher.MatchesCloudEvent(ce))
+
+	ce.SetType("sh.keptn.event.deployment.triggered")
+	assert.False(t, matcher.MatchesCloudEvent(ce))
+}
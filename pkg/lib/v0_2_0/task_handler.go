@@ -0,0 +1,206 @@
+package v0_2_0
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"sync"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn/go-utils/pkg/lib/keptn"
+)
+
+// ErrNoTaskHandler is returned if no handler is registered for the task of an incoming event
+var ErrNoTaskHandler = errors.New("no handler registered for task")
+
+var (
+	keptnHandlerType     = reflect.TypeOf(&Keptn{})
+	eventPropertiesType  = reflect.TypeOf((*keptn.EventProperties)(nil)).Elem()
+	errorType            = reflect.TypeOf((*error)(nil)).Elem()
+	eventDataStructField = reflect.TypeOf(EventData{}).Name()
+)
+
+// TaskHandler handles .triggered events of Keptn tasks by calling the handler function registered for the task.
+// For each .triggered event, a .started event is sent before the handler function is called, and a .finished event
+// containing the result of the handler function is sent afterwards. If the handler function returns an error or panics,
+// the .finished event has the status errored and the result fail.
+// Handler functions may be registered while events are being handled
+type TaskHandler struct {
+	source    string
+	keptnOpts keptn.KeptnOpts
+
+	mu       sync.RWMutex
+	handlers map[string]reflect.Value
+}
+
+// TaskHandlerOption can be used to configure a TaskHandler
+type TaskHandlerOption func(*TaskHandler)
+
+// WithTaskHandlerKeptnOpts sets the options used to create the Keptn handler passed to the handler functions,
+// e.g. to configure the EventSender used to send the .started and .finished events
+func WithTaskHandlerKeptnOpts(opts keptn.KeptnOpts) TaskHandlerOption {
+	return func(h *TaskHandler) {
+		h.keptnOpts = opts
+	}
+}
+
+// NewTaskHandler creates a new TaskHandler. The given source is used as the source of all sent events
+func NewTaskHandler(source string, opts ...TaskHandlerOption) *TaskHandler {
+	h := &TaskHandler{
+		source:   source,
+		handlers: map[string]reflect.Value{},
+	}
+	for _, o := range opts {
+		o(h)
+	}
+	return h
+}
+
+// Register registers the handler function for the given task. The handler function must have the signature
+// func(k *Keptn, data *T) (R, error), where T is the type of the payload of the .triggered event, e.g. DeploymentTriggeredEventData, and R implements
+// keptn.EventProperties, e.g. *DeploymentFinishedEventData. The returned data is used as payload of the .finished
+// event. If it embeds EventData, its status and result default to succeeded and pass. If the returned data is nil,
+// a .finished event with the status succeeded and the result pass is sent
+func (h *TaskHandler) Register(task string, handlerFunc interface{}) error {
+	if task == "" {
+		return errors.New("task must not be empty")
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.handlers[task]; ok {
+		return fmt.Errorf("a handler for task %s is already registered", task)
+	}
+	if err := validateTaskHandlerFunc(handlerFunc); err != nil {
+		return fmt.Errorf("invalid handler for task %s: %s", task, err.Error())
+	}
+	h.handlers[task] = reflect.ValueOf(handlerFunc)
+	return nil
+}
+
+// Topics returns the .triggered event types of all registered tasks, which can be used to subscribe to these events
+func (h *TaskHandler) Topics() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	topics := []string{}
+	for task := range h.handlers {
+		topics = append(topics, GetTriggeredEventType(task))
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// Handle handles the given .triggered event by sending a .started event, calling the handler function registered for
+// its task and sending a .finished event. If no handler is registered for the task, ErrNoTaskHandler is returned.
+// Errors of the handler function are not returned, but sent in the .finished event
+func (h *TaskHandler) Handle(event cloudevents.Event) error {
+	task, kind, err := ParseTaskEventType(event.Type())
+	if err != nil {
+		return err
+	}
+	if "."+kind != keptnTriggeredEventSuffix {
+		return fmt.Errorf("%s is not a .triggered event type", event.Type())
+	}
+	h.mu.RLock()
+	handlerFunc, ok := h.handlers[task]
+	h.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w %s", ErrNoTaskHandler, task)
+	}
+	if _, err := event.Context.GetExtension(keptnContextCEExtension); err != nil {
+		return fmt.Errorf("event %s does not contain a %s", event.ID(), keptnContextCEExtension)
+	}
+
+	k, err := NewKeptn(&event, h.keptnOpts)
+	if err != nil {
+		return fmt.Errorf("could not initialize Keptn handler: %s", err.Error())
+	}
+	if _, err := k.SendTaskStartedEvent(nil, h.source); err != nil {
+		return fmt.Errorf("could not send .started event: %s", err.Error())
+	}
+
+	finishedData := h.runTask(k, handlerFunc, event)
+	if _, err := k.SendTaskFinishedEvent(finishedData, h.source); err != nil {
+		return fmt.Errorf("could not send .finished event: %s", err.Error())
+	}
+	return nil
+}
+
+// runTask decodes the payload of the event and calls the handler function with it, returning the data of the
+// .finished event
+func (h *TaskHandler) runTask(k *Keptn, handlerFunc reflect.Value, event cloudevents.Event) (finishedData keptn.EventProperties) {
+	defer func() {
+		if r := recover(); r != nil {
+			k.Logger.Errorf("handler for %s panicked: %v\n%s", event.Type(), r, debug.Stack())
+			finishedData = erroredEventData(fmt.Sprintf("handler panicked: %v", r))
+		}
+	}()
+
+	data := reflect.New(handlerFunc.Type().In(1).Elem())
+	if err := event.DataAs(data.Interface()); err != nil {
+		return erroredEventData(fmt.Sprintf("could not decode event data: %s", err.Error()))
+	}
+
+	out := handlerFunc.Call([]reflect.Value{reflect.ValueOf(k), data})
+	if err, _ := out[1].Interface().(error); err != nil {
+		k.Logger.Errorf("handler for %s returned an error: %s", event.Type(), err.Error())
+		return erroredEventData(err.Error())
+	}
+	if isNil(out[0]) {
+		return &EventData{Status: StatusSucceeded, Result: ResultPass}
+	}
+	finishedData = out[0].Interface().(keptn.EventProperties)
+	if eventData := getEmbeddedEventData(finishedData); eventData != nil {
+		if eventData.Status == "" {
+			eventData.Status = StatusSucceeded
+		}
+		if eventData.Result == "" {
+			eventData.Result = ResultPass
+		}
+	}
+	return finishedData
+}
+
+func erroredEventData(message string) *EventData {
+	return &EventData{Status: StatusErrored, Result: ResultFailed, Message: message}
+}
+
+func validateTaskHandlerFunc(handlerFunc interface{}) error {
+	t := reflect.TypeOf(handlerFunc)
+	if t == nil || t.Kind() != reflect.Func {
+		return errors.New("handler must be a function")
+	}
+	if t.NumIn() != 2 || t.In(0) != keptnHandlerType || t.In(1).Kind() != reflect.Ptr {
+		return errors.New("handler must accept a *Keptn and a pointer to the event data")
+	}
+	if t.NumOut() != 2 || !t.Out(0).Implements(eventPropertiesType) || t.Out(1) != errorType {
+		return errors.New("handler must return an implementation of keptn.EventProperties and an error")
+	}
+	return nil
+}
+
+// getEmbeddedEventData returns the EventData of the given data if it is an *EventData or a pointer to a struct
+// embedding EventData
+func getEmbeddedEventData(data interface{}) *EventData {
+	if eventData, ok := data.(*EventData); ok {
+		return eventData
+	}
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	field, ok := v.Elem().Type().FieldByName(eventDataStructField)
+	if !ok || !field.Anonymous || field.Type != reflect.TypeOf(EventData{}) {
+		return nil
+	}
+	return v.Elem().FieldByIndex(field.Index).Addr().Interface().(*EventData)
+}
+
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package v0_2_0
+
+import (
+	"errors"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn/go-utils/pkg/lib/keptn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTriggeredEvent(t *testing.T, task string, data interface{}) cloudevents.Event {
+	event := cloudevents.NewEvent()
+	event.SetID("triggered-id")
+	event.SetType(GetTriggeredEventType(task))
+	event.SetSource("shipyard-controller")
+	event.SetExtension(keptnContextCEExtension, "my-context")
+	require.Nil(t, event.SetData(cloudevents.ApplicationJSON, data))
+	return event
+}
+
+func newTestTaskHandler() (*TaskHandler, *TestSender) {
+	sender := &TestSender{}
+	return NewTaskHandler("my-service", WithTaskHandlerKeptnOpts(keptn.KeptnOpts{EventSender: sender})), sender
+}
+
+func getFinishedEventData(t *testing.T, sender *TestSender) EventData {
+	require.Len(t, sender.SentEvents, 2)
+	data := EventData{}
+	require.Nil(t, sender.SentEvents[1].DataAs(&data))
+	return data
+}
+
+func TestTaskHandlerRegister(t *testing.T) {
+	h, _ := newTestTaskHandler()
+
+	assert.Nil(t, h.Register(DeploymentTaskName, func(k *Keptn, data *DeploymentTriggeredEventData) (*DeploymentFinishedEventData, error) {
+		return nil, nil
+	}))
+	assert.Nil(t, h.Register(TestTaskName, func(k *Keptn, data *TestTriggeredEventData) (keptn.EventProperties, error) {
+		return nil, nil
+	}))
+	assert.NotNil(t, h.Register(TestTaskName, func(k *Keptn, data *TestTriggeredEventData) (keptn.EventProperties, error) {
+		return nil, nil
+	}))
+	assert.NotNil(t, h.Register("", func(k *Keptn, data *EventData) (*EventData, error) { return nil, nil }))
+	assert.NotNil(t, h.Register("a", "no function"))
+	assert.NotNil(t, h.Register("b", func(data *EventData) (*EventData, error) { return nil, nil }))
+	assert.NotNil(t, h.Register("c", func(k *Keptn, data EventData) (*EventData, error) { return nil, nil }))
+	assert.NotNil(t, h.Register("d", func(k *Keptn, data *EventData) (EventData, error) { return EventData{}, nil }))
+	assert.NotNil(t, h.Register("e", func(k *Keptn, data *EventData) *EventData { return nil }))
+
+	assert.Equal(t, []string{"sh.keptn.event.deployment.triggered", "sh.keptn.event.test.triggered"}, h.Topics())
+}
+
+func TestTaskHandlerHandle(t *testing.T) {
+	h, sender := newTestTaskHandler()
+	var received *DeploymentTriggeredEventData
+	require.Nil(t, h.Register(DeploymentTaskName, func(k *Keptn, data *DeploymentTriggeredEventData) (*DeploymentFinishedEventData, error) {
+		received = data
+		assert.Equal(t, "my-context", k.KeptnContext)
+		return &DeploymentFinishedEventData{
+			EventData:  EventData{Labels: map[string]string{"deployed-by": "my-service"}},
+			Deployment: DeploymentFinishedData{DeploymentStrategy: "direct"},
+		}, nil
+	}))
+
+	err := h.Handle(newTestTriggeredEvent(t, DeploymentTaskName, DeploymentTriggeredEventData{
+		EventData:  EventData{Project: "sockshop", Stage: "dev", Service: "carts"},
+		Deployment: DeploymentTriggeredData{DeploymentStrategy: "direct"},
+	}))
+	require.Nil(t, err)
+
+	require.NotNil(t, received)
+	assert.Equal(t, "carts", received.Service)
+	assert.Equal(t, "direct", received.Deployment.DeploymentStrategy)
+
+	require.Nil(t, sender.AssertSentEventTypes([]string{"sh.keptn.event.deployment.started", "sh.keptn.event.deployment.finished"}))
+	for _, event := range sender.SentEvents {
+		assert.Equal(t, "my-service", event.Source())
+		triggeredID, _ := event.Context.GetExtension(triggeredIDCEExtension)
+		assert.Equal(t, "triggered-id", triggeredID)
+	}
+
+	finished := DeploymentFinishedEventData{}
+	require.Nil(t, sender.SentEvents[1].DataAs(&finished))
+	assert.Equal(t, StatusSucceeded, finished.Status)
+	assert.Equal(t, ResultPass, finished.Result)
+	assert.Equal(t, "sockshop", finished.Project)
+	assert.Equal(t, "carts", finished.Service)
+	assert.Equal(t, map[string]string{"deployed-by": "my-service"}, finished.Labels)
+	assert.Equal(t, "direct", finished.Deployment.DeploymentStrategy)
+}
+
+func TestTaskHandlerHandleWithoutFinishedData(t *testing.T) {
+	h, sender := newTestTaskHandler()
+	require.Nil(t, h.Register(TestTaskName, func(k *Keptn, data *TestTriggeredEventData) (keptn.EventProperties, error) {
+		return nil, nil
+	}))
+
+	require.Nil(t, h.Handle(newTestTriggeredEvent(t, TestTaskName, EventData{Project: "sockshop", Stage: "dev", Service: "carts"})))
+
+	data := getFinishedEventData(t, sender)
+	assert.Equal(t, StatusSucceeded, data.Status)
+	assert.Equal(t, ResultPass, data.Result)
+}
+
+func TestTaskHandlerHandleError(t *testing.T) {
+	h, sender := newTestTaskHandler()
+	require.Nil(t, h.Register(TestTaskName, func(k *Keptn, data *TestTriggeredEventData) (*TestFinishedEventData, error) {
+		return &TestFinishedEventData{}, errors.New("tests could not be started")
+	}))
+
+	require.Nil(t, h.Handle(newTestTriggeredEvent(t, TestTaskName, EventData{Project: "sockshop", Stage: "dev", Service: "carts"})))
+
+	data := getFinishedEventData(t, sender)
+	assert.Equal(t, StatusErrored, data.Status)
+	assert.Equal(t, ResultFailed, data.Result)
+	assert.Equal(t, "tests could not be started", data.Message)
+	assert.Equal(t, "sockshop", data.Project)
+}
+
+func TestTaskHandlerHandlePanic(t *testing.T) {
+	h, sender := newTestTaskHandler()
+	require.Nil(t, h.Register(TestTaskName, func(k *Keptn, data *TestTriggeredEventData) (*TestFinishedEventData, error) {
+		var labels map[string]string
+		labels["fail"] = "true"
+		return nil, nil
+	}))
+
+	require.Nil(t, h.Handle(newTestTriggeredEvent(t, TestTaskName, EventData{Project: "sockshop", Stage: "dev", Service: "carts"})))
+
+	data := getFinishedEventData(t, sender)
+	assert.Equal(t, StatusErrored, data.Status)
+	assert.Equal(t, ResultFailed, data.Result)
+	assert.Contains(t, data.Message, "handler panicked: assignment to entry in nil map")
+}
+
+func TestTaskHandlerHandleUnknownEvents(t *testing.T) {
+	h, sender := newTestTaskHandler()
+	require.Nil(t, h.Register(TestTaskName, func(k *Keptn, data *TestTriggeredEventData) (*TestFinishedEventData, error) {
+		return nil, nil
+	}))
+
+	err := h.Handle(newTestTriggeredEvent(t, DeploymentTaskName, EventData{}))
+	assert.True(t, errors.Is(err, ErrNoTaskHandler))
+
+	event := newTestTriggeredEvent(t, TestTaskName, EventData{})
+	event.SetType(GetFinishedEventType(TestTaskName))
+	assert.NotNil(t, h.Handle(event))
+
+	event = newTestTriggeredEvent(t, TestTaskName, EventData{})
+	event.SetExtension(keptnContextCEExtension, nil)
+	assert.NotNil(t, h.Handle(event))
+
+	assert.Empty(t, sender.SentEvents)
+}
@@ -0,0 +1,201 @@
+package v0_2_0
+
+import (
+	"strings"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	api "github.com/keptn/go-utils/pkg/api/utils"
+)
+
+// DefaultImplicitTasks are tasks which are not part of shipyard sequences, but are triggered by Keptn services,
+// e.g. the get-sli task triggered by the lighthouse service during an evaluation
+var DefaultImplicitTasks = []string{GetSLITaskName, ConfigureMonitoringTaskName}
+
+// StageTask is a task used by sequences of a stage of a shipyard
+type StageTask struct {
+	Stage string
+	Task  string
+	// Sequences of the stage containing the task
+	Sequences []string
+}
+
+// UnusedSubscription is a subscription of an integration to a task event which is not triggered by the shipyard
+type UnusedSubscription struct {
+	Integration  *models.Integration
+	Subscription models.Subscription
+	Topic        string
+}
+
+// OverlappingSubscribers are different integrations which are all subscribed to the same task of a stage
+type OverlappingSubscribers struct {
+	StageTask
+	Integrations []*models.Integration
+}
+
+// UniformCoverageReport is the result of AnalyzeUniformCoverage
+type UniformCoverageReport struct {
+	// UncoveredTasks are tasks which no integration is subscribed to. Sequences containing such tasks will not finish
+	UncoveredTasks []StageTask
+	// UnusedSubscriptions are subscriptions to tasks which are not used in any stage of the shipyard
+	UnusedSubscriptions []UnusedSubscription
+	// OverlappingSubscribers are tasks which several integrations are subscribed to
+	OverlappingSubscribers []OverlappingSubscribers
+}
+
+// HasFindings returns whether the report contains any finding
+func (r UniformCoverageReport) HasFindings() bool {
+	return len(r.UncoveredTasks) > 0 || len(r.UnusedSubscriptions) > 0 || len(r.OverlappingSubscribers) > 0
+}
+
+// UniformCoverageOption can be used to configure AnalyzeUniformCoverage
+type UniformCoverageOption func(*uniformCoverageAnalysis)
+
+// WithImplicitTasks sets the tasks which are triggered in every stage independently of the shipyard.
+// Subscriptions to these tasks are never reported as unused. By default, DefaultImplicitTasks are used
+func WithImplicitTasks(tasks ...string) UniformCoverageOption {
+	return func(a *uniformCoverageAnalysis) {
+		a.implicitTasks = tasks
+	}
+}
+
+type uniformCoverageAnalysis struct {
+	project       string
+	implicitTasks []string
+}
+
+type integrationKey struct {
+	name      string
+	namespace string
+}
+
+// AnalyzeUniformCoverage checks whether the integrations registered for the project, e.g. retrieved via
+// UniformHandler.GetRegistrations, cover the tasks of the shipyard of the project.
+// A subscription of an integration covers a task of a stage if one of its topics matches the triggered event type of
+// the task and its project and stage filters are empty or match the project and stage. Since the shipyard does not
+// contain services, subscriptions with a service filter are considered to cover the task for the whole stage.
+// Integrations with the same name and namespace are considered as the same integration, so that duplicate
+// registrations of an integration are not reported as overlapping subscribers (see api.DetectStaleIntegrations)
+func AnalyzeUniformCoverage(project string, shipyard Shipyard, integrations []*models.Integration, opts ...UniformCoverageOption) UniformCoverageReport {
+	a := &uniformCoverageAnalysis{
+		project:       project,
+		implicitTasks: DefaultImplicitTasks,
+	}
+	for _, o := range opts {
+		o(a)
+	}
+
+	report := UniformCoverageReport{
+		UncoveredTasks:         []StageTask{},
+		UnusedSubscriptions:    []UnusedSubscription{},
+		OverlappingSubscribers: []OverlappingSubscribers{},
+	}
+
+	for _, task := range getStageTasks(shipyard) {
+		subscribers := []*models.Integration{}
+		seen := map[integrationKey]bool{}
+		for _, integration := range integrations {
+			if integration == nil {
+				continue
+			}
+			key := integrationKey{integration.Name, integration.MetaData.KubernetesMetaData.Namespace}
+			if seen[key] || !a.isSubscribed(integration, task.Stage, task.Task) {
+				continue
+			}
+			seen[key] = true
+			subscribers = append(subscribers, integration)
+		}
+		switch {
+		case len(subscribers) == 0:
+			report.UncoveredTasks = append(report.UncoveredTasks, task)
+		case len(subscribers) > 1:
+			report.OverlappingSubscribers = append(report.OverlappingSubscribers, OverlappingSubscribers{StageTask: task, Integrations: subscribers})
+		}
+	}
+
+	for _, integration := range integrations {
+		if integration == nil {
+			continue
+		}
+		for _, subscription := range getIntegrationSubscriptions(integration) {
+			if !a.matchesProject(subscription.Filter) {
+				continue
+			}
+			for _, topic := range subscription.Topics {
+				if isTaskTriggeredTopic(topic) && !a.isUsed(shipyard, subscription.Filter, topic) {
+					report.UnusedSubscriptions = append(report.UnusedSubscriptions, UnusedSubscription{
+						Integration:  integration,
+						Subscription: subscription,
+						Topic:        topic,
+					})
+				}
+			}
+		}
+	}
+	return report
+}
+
+func (a *uniformCoverageAnalysis) isSubscribed(integration *models.Integration, stage string, task string) bool {
+	for _, subscription := range getIntegrationSubscriptions(integration) {
+		if !a.matchesProject(subscription.Filter) || (subscription.Filter.Stage != "" && subscription.Filter.Stage != stage) {
+			continue
+		}
+		for _, topic := range subscription.Topics {
+			if api.MatchSubscriptionTopic(topic, GetTriggeredEventType(task)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (a *uniformCoverageAnalysis) isUsed(shipyard Shipyard, filter models.SubscriptionFilter, topic string) bool {
+	for _, task := range a.implicitTasks {
+		if api.MatchSubscriptionTopic(topic, GetTriggeredEventType(task)) {
+			return true
+		}
+	}
+	for _, task := range getStageTasks(shipyard) {
+		if (filter.Stage == "" || filter.Stage == task.Stage) && api.MatchSubscriptionTopic(topic, GetTriggeredEventType(task.Task)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *uniformCoverageAnalysis) matchesProject(filter models.SubscriptionFilter) bool {
+	return a.project == "" || filter.Project == "" || filter.Project == a.project
+}
+
+// getStageTasks returns the tasks of each stage of the shipyard in the order of their first occurrence
+func getStageTasks(shipyard Shipyard) []StageTask {
+	tasks := []StageTask{}
+	for _, stage := range shipyard.Spec.Stages {
+		index := map[string]int{}
+		for _, sequence := range stage.Sequences {
+			for _, task := range sequence.Tasks {
+				i, ok := index[task.Name]
+				if !ok {
+					i = len(tasks)
+					index[task.Name] = i
+					tasks = append(tasks, StageTask{Stage: stage.Name, Task: task.Name})
+				}
+				if sequences := tasks[i].Sequences; len(sequences) == 0 || sequences[len(sequences)-1] != sequence.Name {
+					tasks[i].Sequences = append(tasks[i].Sequences, sequence.Name)
+				}
+			}
+		}
+	}
+	return tasks
+}
+
+func getIntegrationSubscriptions(integration *models.Integration) []models.Subscription {
+	return append([]models.Subscription{integration.Subscription}, integration.Subscriptions...)
+}
+
+// isTaskTriggeredTopic checks whether the topic refers to triggered events of specific tasks like
+// "sh.keptn.event.test.triggered" or "sh.keptn.event.*.triggered". Topics for other event kinds or sequence events
+// are not related to tasks of the shipyard
+func isTaskTriggeredTopic(topic string) bool {
+	return IsTaskEventType(topic) && strings.HasPrefix(topic, keptnEventTypePrefix) &&
+		strings.HasSuffix(topic, keptnTriggeredEventSuffix) && !strings.Contains(topic, ">")
+}
@@ -0,0 +1,106 @@
+package v0_2_0
+
+import (
+	"testing"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const coverageTestShipyard = `apiVersion: spec.keptn.sh/0.2.0
+kind: Shipyard
+metadata:
+  name: shipyard-sockshop
+spec:
+  stages:
+    - name: dev
+      sequences:
+        - name: delivery
+          tasks:
+            - name: deployment
+            - name: test
+            - name: evaluation
+        - name: rollback
+          tasks:
+            - name: rollback
+    - name: production
+      sequences:
+        - name: delivery
+          tasks:
+            - name: approval
+            - name: deployment
+            - name: release
+`
+
+func newCoverageTestIntegration(name, namespace string, subscriptions ...models.Subscription) *models.Integration {
+	integration := &models.Integration{ID: name + "-" + namespace, Name: name}
+	integration.MetaData.KubernetesMetaData.Namespace = namespace
+	integration.Subscriptions = subscriptions
+	return integration
+}
+
+func TestAnalyzeUniformCoverage(t *testing.T) {
+	shipyard, err := DecodeShipyardYAML([]byte(coverageTestShipyard))
+	require.Nil(t, err)
+
+	helm := newCoverageTestIntegration("helm-service", "keptn",
+		models.Subscription{Topics: []string{"sh.keptn.event.deployment.triggered", "sh.keptn.event.release.triggered", "sh.keptn.event.rollback.triggered"}},
+	)
+	helmDuplicate := newCoverageTestIntegration("helm-service", "keptn",
+		models.Subscription{Topics: []string{"sh.keptn.event.deployment.triggered"}},
+	)
+	jmeter := newCoverageTestIntegration("jmeter-service", "keptn",
+		models.Subscription{Topics: []string{"sh.keptn.event.test.triggered"}, Filter: models.SubscriptionFilter{Project: "sockshop", Stage: "dev"}},
+		models.Subscription{Topics: []string{"sh.keptn.event.load-test.triggered"}},
+	)
+	argo := newCoverageTestIntegration("argo-service", "keptn-exec",
+		models.Subscription{Topics: []string{"sh.keptn.event.deployment.triggered"}, Filter: models.SubscriptionFilter{Stage: "production"}},
+		models.Subscription{Topics: []string{"sh.keptn.event.release.triggered"}, Filter: models.SubscriptionFilter{Stage: "staging"}},
+	)
+	lighthouse := newCoverageTestIntegration("lighthouse-service", "keptn",
+		models.Subscription{Topics: []string{"sh.keptn.event.evaluation.triggered", "sh.keptn.event.get-sli.finished"}},
+	)
+	prometheus := newCoverageTestIntegration("prometheus-service", "keptn",
+		models.Subscription{Topics: []string{"sh.keptn.event.get-sli.triggered"}},
+	)
+	otherProject := newCoverageTestIntegration("approval-service", "keptn",
+		models.Subscription{Topics: []string{"sh.keptn.event.approval.triggered"}, Filter: models.SubscriptionFilter{Project: "podtato"}},
+	)
+
+	report := AnalyzeUniformCoverage("sockshop", *shipyard, []*models.Integration{helm, helmDuplicate, jmeter, argo, lighthouse, prometheus, otherProject, nil})
+	require.True(t, report.HasFindings())
+
+	assert.Equal(t, []StageTask{{Stage: "production", Task: "approval", Sequences: []string{"delivery"}}}, report.UncoveredTasks)
+
+	require.Len(t, report.OverlappingSubscribers, 1)
+	assert.Equal(t, StageTask{Stage: "production", Task: "deployment", Sequences: []string{"delivery"}}, report.OverlappingSubscribers[0].StageTask)
+	assert.Equal(t, []*models.Integration{helm, argo}, report.OverlappingSubscribers[0].Integrations)
+
+	require.Len(t, report.UnusedSubscriptions, 2)
+	assert.Equal(t, jmeter, report.UnusedSubscriptions[0].Integration)
+	assert.Equal(t, "sh.keptn.event.load-test.triggered", report.UnusedSubscriptions[0].Topic)
+	assert.Equal(t, argo, report.UnusedSubscriptions[1].Integration)
+	assert.Equal(t, "sh.keptn.event.release.triggered", report.UnusedSubscriptions[1].Topic)
+	assert.Equal(t, "staging", report.UnusedSubscriptions[1].Subscription.Filter.Stage)
+}
+
+func TestAnalyzeUniformCoverageWithImplicitTasks(t *testing.T) {
+	shipyard := Shipyard{Spec: ShipyardSpec{Stages: []Stage{{
+		Name:      "dev",
+		Sequences: []Sequence{{Name: "delivery", Tasks: []Task{{Name: "deployment"}}}},
+	}}}}
+	integrations := []*models.Integration{
+		newCoverageTestIntegration("helm-service", "keptn", models.Subscription{Topics: []string{"sh.keptn.event.*.triggered"}}),
+		newCoverageTestIntegration("prometheus-service", "keptn", models.Subscription{Topics: []string{"sh.keptn.event.get-sli.triggered"}}),
+	}
+
+	report := AnalyzeUniformCoverage("sockshop", shipyard, integrations)
+	assert.False(t, report.HasFindings())
+
+	report = AnalyzeUniformCoverage("sockshop", shipyard, integrations, WithImplicitTasks())
+	require.Len(t, report.UnusedSubscriptions, 1)
+	assert.Equal(t, "sh.keptn.event.get-sli.triggered", report.UnusedSubscriptions[0].Topic)
+	assert.Empty(t, report.UncoveredTasks)
+	assert.Empty(t, report.OverlappingSubscribers)
+}
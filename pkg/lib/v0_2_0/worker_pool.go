@@ -0,0 +1,245 @@
+package v0_2_0
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// DefaultWorkerCount is the number of workers of a WorkerPool by default
+const DefaultWorkerCount = 10
+
+// DefaultQueueLimit is the maximum number of queued jobs of a WorkerPool by default
+const DefaultQueueLimit = 1000
+
+// ErrQueueFull is returned if a job is submitted to a WorkerPool whose queue limit is reached
+var ErrQueueFull = errors.New("worker pool queue is full")
+
+// ErrWorkerPoolStopped is returned if a job is submitted to a WorkerPool which is shut down
+var ErrWorkerPoolStopped = errors.New("worker pool is stopped")
+
+// WorkerPool runs jobs with a bounded number of workers. Jobs are submitted with a key, e.g. the shkeptncontext of an
+// event: jobs with the same key are run one after another in the order they were submitted, while jobs with
+// different keys are run in parallel. Jobs which can not be started immediately are queued up to the queue limit
+type WorkerPool struct {
+	workerCount      int
+	queueLimit       int
+	drainOnShutdown  bool
+	queueDepthHook   func(depth int)
+	activeWorkerHook func(active int)
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	pending  map[string][]func()
+	active   map[string]bool
+	ready    []string
+	queued   int
+	running  int
+	stopped  bool
+	finished sync.WaitGroup
+}
+
+// WorkerPoolOption can be used to configure a WorkerPool
+type WorkerPoolOption func(*WorkerPool)
+
+// WithWorkerCount sets the number of workers, i.e. the maximum number of jobs run in parallel
+func WithWorkerCount(count int) WorkerPoolOption {
+	return func(p *WorkerPool) {
+		p.workerCount = count
+	}
+}
+
+// WithQueueLimit sets the maximum number of jobs waiting to be run. If the limit is reached, Submit returns ErrQueueFull.
+// Since each job waits in the queue until a worker picks it up, the limit is at least 1
+func WithQueueLimit(limit int) WorkerPoolOption {
+	return func(p *WorkerPool) {
+		p.queueLimit = limit
+	}
+}
+
+// WithDrainOnShutdown makes Shutdown run all queued jobs before stopping the workers.
+// By default, queued jobs which have not been started yet are dropped on shutdown
+func WithDrainOnShutdown() WorkerPoolOption {
+	return func(p *WorkerPool) {
+		p.drainOnShutdown = true
+	}
+}
+
+// WithQueueDepthHook sets a function which is called with the number of queued jobs whenever it changes,
+// e.g. to expose it as a metric. The hook must not call methods of the WorkerPool
+func WithQueueDepthHook(hook func(depth int)) WorkerPoolOption {
+	return func(p *WorkerPool) {
+		p.queueDepthHook = hook
+	}
+}
+
+// WithActiveWorkerHook sets a function which is called with the number of workers running a job whenever it changes,
+// e.g. to expose it as a metric. The hook must not call methods of the WorkerPool
+func WithActiveWorkerHook(hook func(active int)) WorkerPoolOption {
+	return func(p *WorkerPool) {
+		p.activeWorkerHook = hook
+	}
+}
+
+// NewWorkerPool creates a new WorkerPool and starts its workers
+func NewWorkerPool(opts ...WorkerPoolOption) *WorkerPool {
+	p := &WorkerPool{
+		workerCount: DefaultWorkerCount,
+		queueLimit:  DefaultQueueLimit,
+		pending:     map[string][]func(){},
+		active:      map[string]bool{},
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	if p.workerCount < 1 {
+		p.workerCount = 1
+	}
+	if p.queueLimit < 1 {
+		p.queueLimit = 1
+	}
+	p.cond = sync.NewCond(&p.mu)
+
+	p.finished.Add(p.workerCount)
+	for i := 0; i < p.workerCount; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// Submit queues the job to be run after all previously submitted jobs with the same key.
+// If the queue limit is reached, ErrQueueFull is returned; after Shutdown, ErrWorkerPoolStopped is returned
+func (p *WorkerPool) Submit(key string, job func()) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		return ErrWorkerPoolStopped
+	}
+	if p.queued >= p.queueLimit {
+		return ErrQueueFull
+	}
+	if len(p.pending[key]) == 0 && !p.active[key] {
+		p.ready = append(p.ready, key)
+	}
+	p.pending[key] = append(p.pending[key], job)
+	p.setQueued(p.queued + 1)
+	p.cond.Signal()
+	return nil
+}
+
+// QueueDepth returns the number of jobs waiting to be run
+func (p *WorkerPool) QueueDepth() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.queued
+}
+
+// Shutdown stops accepting jobs and waits until the workers are done, or until the context is done.
+// Running jobs are always completed. Queued jobs are only run if the pool has been created with WithDrainOnShutdown
+func (p *WorkerPool) Shutdown(ctx context.Context) error {
+	return p.shutdown(ctx, p.drainOnShutdown)
+}
+
+// shutdown stops accepting jobs and waits until the workers are done, or until the context is done.
+// If drain is false, queued jobs are dropped
+func (p *WorkerPool) shutdown(ctx context.Context, drain bool) error {
+	p.mu.Lock()
+	p.stopped = true
+	if !drain && p.queued > 0 {
+		log.Printf("dropping %d queued jobs on shutdown", p.queued)
+		p.pending = map[string][]func(){}
+		p.ready = nil
+		p.setQueued(0)
+	}
+	p.cond.Broadcast()
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.finished.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("jobs are still running: %w", ctx.Err())
+	}
+}
+
+func (p *WorkerPool) work() {
+	defer p.finished.Done()
+	for {
+		key, job, ok := p.next()
+		if !ok {
+			return
+		}
+		p.run(job)
+		p.done(key)
+	}
+}
+
+// next waits for a job whose key is not active and marks the key as active.
+// It returns false if the pool is stopped and there are no more jobs to run
+func (p *WorkerPool) next() (string, func(), bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for len(p.ready) == 0 {
+		if p.stopped && p.queued == 0 {
+			// wake up the other workers waiting for jobs
+			p.cond.Broadcast()
+			return "", nil, false
+		}
+		p.cond.Wait()
+	}
+	key := p.ready[0]
+	p.ready = p.ready[1:]
+	job := p.pending[key][0]
+	p.pending[key] = p.pending[key][1:]
+	p.active[key] = true
+	p.setQueued(p.queued - 1)
+	p.setRunning(p.running + 1)
+	return key, job, true
+}
+
+// done marks the key as inactive again, so that the next job with the key can be run
+func (p *WorkerPool) done(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.active, key)
+	if len(p.pending[key]) > 0 {
+		p.ready = append(p.ready, key)
+		p.cond.Signal()
+	} else {
+		delete(p.pending, key)
+	}
+	p.setRunning(p.running - 1)
+	if p.stopped && p.queued == 0 {
+		p.cond.Broadcast()
+	}
+}
+
+func (p *WorkerPool) run(job func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("job of worker pool panicked: %v", r)
+		}
+	}()
+	job()
+}
+
+func (p *WorkerPool) setQueued(queued int) {
+	p.queued = queued
+	if p.queueDepthHook != nil {
+		p.queueDepthHook(queued)
+	}
+}
+
+func (p *WorkerPool) setRunning(running int) {
+	p.running = running
+	if p.activeWorkerHook != nil {
+		p.activeWorkerHook(running)
+	}
+}
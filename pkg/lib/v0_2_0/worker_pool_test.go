@@ -0,0 +1,203 @@
+package v0_2_0
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerPoolOrdersJobsPerKey(t *testing.T) {
+	pool := NewWorkerPool(WithWorkerCount(4))
+
+	var mu sync.Mutex
+	results := map[string][]int{}
+	for i := 0; i < 20; i++ {
+		for _, key := range []string{"context-a", "context-b", "context-c"} {
+			i, key := i, key
+			require.Nil(t, pool.Submit(key, func() {
+				time.Sleep(time.Millisecond)
+				mu.Lock()
+				results[key] = append(results[key], i)
+				mu.Unlock()
+			}))
+		}
+	}
+	require.Nil(t, pool.Shutdown(context.Background()))
+
+	// without WithDrainOnShutdown, queued jobs may have been dropped, but the run jobs have to be in order
+	for key, got := range results {
+		for i := range got {
+			assert.Equal(t, i, got[i], "jobs of %s run out of order: %v", key, got)
+		}
+	}
+}
+
+func TestWorkerPoolRunsDifferentKeysInParallel(t *testing.T) {
+	pool := NewWorkerPool(WithWorkerCount(2), WithDrainOnShutdown())
+
+	started := make(chan string, 3)
+	release := make(chan struct{})
+	for _, key := range []string{"context-a", "context-a", "context-b"} {
+		key := key
+		require.Nil(t, pool.Submit(key, func() {
+			started <- key
+			<-release
+		}))
+	}
+
+	got := []string{<-started, <-started}
+	assert.ElementsMatch(t, []string{"context-a", "context-b"}, got)
+	select {
+	case key := <-started:
+		t.Fatalf("job of %s started before the previous job of the same context was done", key)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	require.Nil(t, pool.Shutdown(context.Background()))
+	assert.Equal(t, "context-a", <-started)
+}
+
+func TestWorkerPoolQueueLimitAndHooks(t *testing.T) {
+	var mu sync.Mutex
+	depths := []int{}
+	maxActive := 0
+	pool := NewWorkerPool(
+		WithWorkerCount(1),
+		WithQueueLimit(2),
+		WithQueueDepthHook(func(depth int) {
+			mu.Lock()
+			depths = append(depths, depth)
+			mu.Unlock()
+		}),
+		WithActiveWorkerHook(func(active int) {
+			mu.Lock()
+			if active > maxActive {
+				maxActive = active
+			}
+			mu.Unlock()
+		}),
+	)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	require.Nil(t, pool.Submit("context-a", func() {
+		close(started)
+		<-release
+	}))
+	<-started
+	require.Nil(t, pool.Submit("context-b", func() {}))
+	require.Nil(t, pool.Submit("context-c", func() {}))
+	assert.Equal(t, 2, pool.QueueDepth())
+	assert.True(t, errors.Is(pool.Submit("context-d", func() {}), ErrQueueFull))
+
+	close(release)
+	require.Nil(t, pool.Shutdown(context.Background()))
+	assert.True(t, errors.Is(pool.Submit("context-d", func() {}), ErrWorkerPoolStopped))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, maxActive)
+	assert.Equal(t, 0, depths[len(depths)-1])
+	assert.Contains(t, depths, 2)
+}
+
+func TestWorkerPoolQueueLimitIsAtLeastOne(t *testing.T) {
+	pool := NewWorkerPool(WithQueueLimit(0))
+	done := make(chan struct{})
+	require.Nil(t, pool.Submit("context-a", func() { close(done) }))
+	<-done
+	require.Nil(t, pool.Shutdown(context.Background()))
+}
+
+func TestWorkerPoolShutdown(t *testing.T) {
+	for _, drain := range []bool{true, false} {
+		t.Run(fmt.Sprintf("drain %v", drain), func(t *testing.T) {
+			opts := []WorkerPoolOption{WithWorkerCount(1)}
+			if drain {
+				opts = append(opts, WithDrainOnShutdown())
+			}
+			pool := NewWorkerPool(opts...)
+
+			started := make(chan struct{})
+			release := make(chan struct{})
+			var mu sync.Mutex
+			run := 0
+			require.Nil(t, pool.Submit("context-a", func() {
+				close(started)
+				<-release
+				mu.Lock()
+				run++
+				mu.Unlock()
+			}))
+			<-started
+			for i := 0; i < 3; i++ {
+				require.Nil(t, pool.Submit("context-b", func() {
+					mu.Lock()
+					run++
+					mu.Unlock()
+				}))
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			assert.True(t, errors.Is(pool.Shutdown(ctx), context.DeadlineExceeded))
+
+			close(release)
+			require.Nil(t, pool.Shutdown(context.Background()))
+			mu.Lock()
+			defer mu.Unlock()
+			if drain {
+				assert.Equal(t, 4, run)
+			} else {
+				assert.Equal(t, 1, run)
+			}
+		})
+	}
+}
+
+func TestEventReceiverWithWorkerPool(t *testing.T) {
+	var mu sync.Mutex
+	handled := []string{}
+	release := make(chan struct{})
+	// the queued events are run on shutdown, although the pool is not created with WithDrainOnShutdown
+	receiver := NewEventReceiver(WithWorkerPool(NewWorkerPool(WithWorkerCount(2), WithQueueLimit(2))))
+	receiver.Handle("sh.keptn.event.>", func(event models.KeptnContextExtendedCE) error {
+		<-release
+		mu.Lock()
+		handled = append(handled, event.ID)
+		mu.Unlock()
+		return nil
+	})
+	ts := httptest.NewServer(receiver)
+	defer ts.Close()
+
+	send := func(id string) int {
+		body := fmt.Sprintf(`{"specversion":"1.0","id":"%s","type":"sh.keptn.event.test.triggered","source":"test","shkeptncontext":"my-context"}`, id)
+		resp, err := http.Post(ts.URL, "application/cloudevents+json", strings.NewReader(body))
+		require.Nil(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// the first event is run immediately, the next two are queued since they belong to the same context
+	assert.Equal(t, http.StatusAccepted, send("1"))
+	require.Eventually(t, func() bool { return receiver.workerPool.QueueDepth() == 0 }, time.Second, time.Millisecond)
+	assert.Equal(t, http.StatusAccepted, send("2"))
+	assert.Equal(t, http.StatusAccepted, send("3"))
+	assert.Equal(t, http.StatusTooManyRequests, send("4"))
+
+	close(release)
+	require.Nil(t, receiver.Shutdown(context.Background()))
+	assert.Equal(t, []string{"1", "2", "3"}, handled)
+}